@@ -0,0 +1,290 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/config"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
+	rules "github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	promqlVar "github.com/perses/perses/go-sdk/prometheus/variable/promql"
+	staticListVar "github.com/perses/perses/go-sdk/variable/plugin/static-list"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/promqltest"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// The series of testdata/series are loaded every minute for an hour, and the panels are queried over the
+// last half hour, leaving the rates their first samples.
+const (
+	queryStep  = time.Minute
+	queryStart = 30 * time.Minute
+	queryEnd   = 60 * time.Minute
+)
+
+// builtinVariables holds the values of the variables Perses sets itself for the queried time range.
+var builtinVariables = map[string]string{
+	"__interval":      "1m",
+	"__interval_ms":   "60000",
+	"__rate_interval": "4m",
+	"__range":         "30m",
+	"__range_s":       "1800",
+	"__range_ms":      "1800000",
+}
+
+// variableRegexp matches the Perses variable syntaxes: $var, ${var} and ${var:format}.
+var variableRegexp = regexp.MustCompile(`\$(?:\{(\w+)(?::\w+)?\}|(\w+))`)
+
+// TestDashboardQueries loads the synthetic series of every mixin in an in-process Prometheus engine, evaluates
+// the recording rules of the mixin over them, then runs every panel query of its dashboards with the variables
+// set to the first value they offer, and fails on the queries returning no data. Run it with:
+//
+//	go test -tags integration -run TestDashboardQueries .
+func TestDashboardQueries(t *testing.T) {
+	for _, mixinType := range config.MixinTypes {
+		for _, clusterLabelName := range []string{"", "cluster"} {
+			// The extra labels must not keep the recorded series from matching the series they are joined with.
+			for _, extraLabels := range []map[string]string{nil, {"environment": "production"}} {
+				name := mixinType
+				if clusterLabelName != "" {
+					name += "/" + clusterLabelName
+				}
+				if extraLabels != nil {
+					name += "/extra-labels"
+				}
+				t.Run(name, func(t *testing.T) {
+					testMixinQueries(t, config.Mixin{Type: mixinType, ClusterLabelName: clusterLabelName, ExtraLabels: extraLabels})
+				})
+			}
+		}
+	}
+}
+
+func testMixinQueries(t *testing.T, mixin config.Mixin) {
+	series, err := os.ReadFile(filepath.Join("testdata", "series", mixin.Type+".test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage := promqltest.LoadedStorage(t, string(series))
+	defer testStorage.Close()
+	engine := promqltest.NewTestEngine(t, false, 0, promqltest.DefaultMaxSamplesPerQuery)
+
+	ruleOptions, err := mixin.RuleOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ruleWriter := rules.NewRuleWriter(rules.PrometheusOutput, t.TempDir())
+	addRules(ruleWriter, mixin.Type, ruleOptions)
+	ruleFiles, err := ruleWriter.RuleFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ruleFile := range ruleFiles {
+		for _, group := range ruleFile.Groups {
+			for _, rule := range group.Rules {
+				if rule.Record == "" {
+					continue
+				}
+				if err := record(engine, testStorage, rule.Record, rule.Expr, rule.Labels); err != nil {
+					t.Fatalf("recording rule %q: %v", rule.Record, err)
+				}
+			}
+		}
+	}
+
+	dashboardOptions, err := mixin.DashboardOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dashboardWriter := dashboards.NewDashboardWriter(dashboards.Output{Format: dashboards.YAMLOutput, Dir: t.TempDir()})
+	addDashboards(dashboardWriter, mixin.Type, dashboardOptions)
+	builtDashboards, err := dashboardWriter.Dashboards()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range builtDashboards {
+		testDashboardQueries(t, engine, testStorage, d)
+	}
+}
+
+// record evaluates a recording rule over the loaded series and appends its result to the storage, as
+// Prometheus would have every minute, so that the rules and queries using the recorded metric find it.
+func record(engine *promql.Engine, s storage.Storage, name string, expr string, ruleLabels map[string]string) error {
+	matrix, err := rangeQuery(engine, s, expr, 0, queryEnd)
+	if err != nil {
+		return err
+	}
+
+	appender := s.Appender(context.Background())
+	for _, series := range matrix {
+		builder := labels.NewBuilder(series.Metric).Set(labels.MetricName, name)
+		for labelName, value := range ruleLabels {
+			builder.Set(labelName, value)
+		}
+		seriesLabels := builder.Labels()
+		for _, point := range series.Floats {
+			if _, err := appender.Append(0, seriesLabels, point.T, point.F); err != nil {
+				_ = appender.Rollback()
+				return err
+			}
+		}
+	}
+	return appender.Commit()
+}
+
+func testDashboardQueries(t *testing.T, engine *promql.Engine, s storage.Storage, d v1.Dashboard) {
+	t.Helper()
+	d, err := perses.Normalize(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]string{}
+	for name, value := range builtinVariables {
+		values[name] = value
+	}
+	for _, v := range d.Spec.Variables {
+		name := v.Spec.GetName()
+		value, err := variableValue(engine, s, v, values)
+		if err != nil {
+			t.Errorf("dashboard %q: variable %q: %v", d.Metadata.Name, name, err)
+			return
+		}
+		values[name] = value
+	}
+
+	panels, err := perses.Panels(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, panel := range panels {
+		queries, err := perses.PanelQueries(panel.Panel)
+		if err != nil {
+			t.Fatalf("dashboard %q: panel %q: %v", d.Metadata.Name, panel.Name(), err)
+		}
+		for i, query := range queries {
+			expr, err := interpolate(query.Query, values)
+			if err == nil {
+				var matrix promql.Matrix
+				if matrix, err = rangeQuery(engine, s, expr, queryStart, queryEnd); err == nil && len(matrix) == 0 {
+					err = fmt.Errorf("no data")
+				}
+			}
+			if err != nil {
+				t.Errorf("dashboard %q: panel %q query %d: %v\n%s", d.Metadata.Name, panel.Name(), i+1, err, expr)
+			}
+		}
+	}
+}
+
+// variableValue returns the value of a variable the panel queries are run with: the default value of the
+// static lists and the first value of the Prometheus variables, whose queries reference the previous variables.
+func variableValue(engine *promql.Engine, s storage.Storage, v dashboard.Variable, values map[string]string) (string, error) {
+	switch spec := v.Spec.(type) {
+	case *dashboard.TextVariableSpec:
+		return interpolate(spec.Value, values)
+	case *dashboard.ListVariableSpec:
+		if spec.Plugin.Kind == staticListVariableKind {
+			var pluginSpec staticListVar.PluginSpec
+			if err := perses.DecodePluginSpec(spec.Plugin, &pluginSpec); err != nil {
+				return "", err
+			}
+			value := ""
+			if spec.DefaultValue != nil {
+				value = spec.DefaultValue.SingleValue
+			}
+			if value == "" && len(pluginSpec.Values) > 0 {
+				value = pluginSpec.Values[0]
+			}
+			return interpolate(value, values)
+		}
+
+		var labelName string
+		var exprs []string
+		switch spec.Plugin.Kind {
+		case labelValuesVar.PluginKind:
+			var pluginSpec labelValuesVar.PluginSpec
+			if err := perses.DecodePluginSpec(spec.Plugin, &pluginSpec); err != nil {
+				return "", err
+			}
+			labelName, exprs = pluginSpec.LabelName, pluginSpec.Matchers
+		case promqlVar.PluginKind:
+			var pluginSpec promqlVar.PluginSpec
+			if err := perses.DecodePluginSpec(spec.Plugin, &pluginSpec); err != nil {
+				return "", err
+			}
+			labelName, exprs = pluginSpec.LabelName, []string{pluginSpec.Expr}
+		default:
+			return "", fmt.Errorf("unsupported variable plugin %q", spec.Plugin.Kind)
+		}
+
+		var labelValues []string
+		for _, expr := range exprs {
+			expr, err := interpolate(expr, values)
+			if err != nil {
+				return "", err
+			}
+			matrix, err := rangeQuery(engine, s, fmt.Sprintf("group by (%s) (%s)", labelName, expr), queryEnd, queryEnd)
+			if err != nil {
+				return "", err
+			}
+			for _, series := range matrix {
+				if value := series.Metric.Get(labelName); value != "" {
+					labelValues = append(labelValues, value)
+				}
+			}
+		}
+		if len(labelValues) == 0 {
+			return "", fmt.Errorf("no value found for label %q", labelName)
+		}
+		sort.Strings(labelValues)
+		return labelValues[0], nil
+	}
+	return "", fmt.Errorf("unsupported variable kind %q", v.Kind)
+}
+
+// staticListVariableKind is the plugin kind of the static list variables, which the SDK does not export.
+const staticListVariableKind = "StaticListVariable"
+
+// interpolate replaces the variables of the query by their value, failing on undefined ones.
+func interpolate(query string, values map[string]string) (string, error) {
+	var err error
+	result := variableRegexp.ReplaceAllStringFunc(query, func(match string) string {
+		groups := variableRegexp.FindStringSubmatch(match)
+		name := groups[1] + groups[2]
+		value, ok := values[name]
+		if !ok {
+			err = fmt.Errorf("undefined variable %q", name)
+			return match
+		}
+		return value
+	})
+	return result, err
+}
+
+// rangeQuery runs the query between the offsets from the start of the loaded series.
+func rangeQuery(engine *promql.Engine, s storage.Storage, expr string, start time.Duration, end time.Duration) (promql.Matrix, error) {
+	query, err := engine.NewRangeQuery(context.Background(), s, nil, expr, time.Unix(0, 0).Add(start), time.Unix(0, 0).Add(end), queryStep)
+	if err != nil {
+		return nil, err
+	}
+	defer query.Close()
+	result := query.Exec(context.Background())
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result.Matrix()
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PrometheusMixin   = "prometheus"
+	NodeExporterMixin = "node-exporter"
+	AlertmanagerMixin = "alertmanager"
+)
+
+// MixinTypes lists the mixins that can be rendered, in rendering order.
+var MixinTypes = []string{PrometheusMixin, NodeExporterMixin, AlertmanagerMixin}
+
+// DefaultSelectors holds the selector of the mixins that do not set one.
+var DefaultSelectors = map[string]string{
+	NodeExporterMixin: `job="node"`,
+}
+
+// Config lists the mixins to render. The same mixin type can be listed several times, for instance
+// once per environment, as long as each entry writes to its own output directory.
+type Config struct {
+	Mixins []Mixin `json:"mixins" yaml:"mixins"`
+}
+
+// Mixin holds the settings used to render the dashboards and rules of one mixin.
+type Mixin struct {
+	// Type is the mixin to render: prometheus, node-exporter or alertmanager.
+	Type string `json:"type" yaml:"type"`
+	// Project is the Perses project of the dashboards, default when empty.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	// Datasource is the Prometheus datasource of the dashboards, the project default one when empty.
+	Datasource string `json:"datasource,omitempty" yaml:"datasource,omitempty"`
+	// Datasources adds a datasource variable switching the dashboards between the listed datasources,
+	// Datasource being its default value.
+	Datasources []string `json:"datasources,omitempty" yaml:"datasources,omitempty"`
+	// ClusterLabelName adds a cluster variable to the dashboards when set.
+	ClusterLabelName string `json:"clusterLabelName,omitempty" yaml:"clusterLabelName,omitempty"`
+	// ScopeLabels adds a variable per label, such as region or namespace, filtering the dashboards further.
+	ScopeLabels []string `json:"scopeLabels,omitempty" yaml:"scopeLabels,omitempty"`
+	// Selector selects the series of the mixin, such as job=~"node.*". See DefaultSelectors when empty.
+	Selector *string `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// Version is the version of the exporter the dashboards and rules target, such as 2.53, only supported by
+	// the prometheus mixin. The queries select both names of the renamed metrics when empty.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// RateInterval is the default range of the rates in the dashboards, $__rate_interval when empty.
+	RateInterval string `json:"rateInterval,omitempty" yaml:"rateInterval,omitempty"`
+	// ExtraLabels are added to every alerting rule of the mixin, not to the recording rules.
+	ExtraLabels map[string]string `json:"extraLabels,omitempty" yaml:"extraLabels,omitempty"`
+	// OutputDir is where the dashboards are written, the -output-dir flag when empty.
+	OutputDir string `json:"outputDir,omitempty" yaml:"outputDir,omitempty"`
+	// RulesOutputDir is where the rules are written. It defaults to the rules directory of OutputDir
+	// when OutputDir is set, and to the -rules-output-dir flag otherwise.
+	RulesOutputDir string `json:"rulesOutputDir,omitempty" yaml:"rulesOutputDir,omitempty"`
+}
+
+// Schema is the JSON Schema of the configuration files, which editors can use to complete and check them.
+//
+//go:embed schema.json
+var Schema []byte
+
+var schema = compileSchema()
+
+// schemaURL is the $id of Schema.
+const schemaURL = "https://github.com/nicolastakashi/community-perses-dashboards/internal/config/schema.json"
+
+func compileSchema() *jsonschema.Schema {
+	document, err := jsonschema.UnmarshalJSON(bytes.NewReader(Schema))
+	if err != nil {
+		panic(fmt.Sprintf("invalid configuration schema: %v", err))
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaURL, document); err != nil {
+		panic(fmt.Sprintf("invalid configuration schema: %v", err))
+	}
+	return compiler.MustCompile(schemaURL)
+}
+
+// Load reads a configuration file, as JSON when its extension is .json and as YAML otherwise.
+// The file is checked against Schema, which rejects the unknown fields, then the configuration is validated.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	isJSON := strings.EqualFold(filepath.Ext(path), ".json")
+	var document any
+	if isJSON {
+		document, err = jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	} else {
+		var node yaml.Node
+		err = yaml.Unmarshal(data, &node)
+		document = yamlValue(&node)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("unable to decode %q: %w", path, err)
+	}
+	if err := schema.Validate(document); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration %q: %w", path, err)
+	}
+
+	var config Config
+	if isJSON {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("unable to decode %q: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration %q: %w", path, err)
+	}
+	return config, nil
+}
+
+// yamlValue returns the value of a YAML node as if it was decoded from JSON, to be checked against the schema.
+// The scalars are strings, as they are once decoded into the fields of the configuration, all of them being
+// strings, and the null fields are left out, as they are left empty.
+func yamlValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.MappingNode:
+		m := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if value := node.Content[i+1]; value.ShortTag() != "!!null" {
+				m[node.Content[i].Value] = yamlValue(value)
+			}
+		}
+		return m
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			s = append(s, yamlValue(item))
+		}
+		return s
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.ScalarNode:
+		if node.ShortTag() != "!!null" {
+			return node.Value
+		}
+	}
+	return nil
+}
+
+// Validate checks every mixin of the configuration and returns all the errors found. Unlike Schema, it
+// also checks that the selectors parse, the versions are known and the mixins do not overwrite each other,
+// and it applies to the configurations built from the flags.
+func (c Config) Validate() error {
+	if len(c.Mixins) == 0 {
+		return errors.New("mixins: at least one mixin is required")
+	}
+
+	var errs []error
+	outputs := map[string]int{}
+	for i, mixin := range c.Mixins {
+		if mixinErrs := mixin.validate(); len(mixinErrs) > 0 {
+			for _, err := range mixinErrs {
+				errs = append(errs, fmt.Errorf("mixins[%d].%w", i, err))
+			}
+			continue
+		}
+		// Two entries of the same mixin writing to the same directory would overwrite each other.
+		for _, output := range []string{"dashboards:" + filepath.Clean(mixin.OutputDir), "rules:" + filepath.Clean(mixin.RulesDir(""))} {
+			key := mixin.Type + ":" + output
+			if j, ok := outputs[key]; ok {
+				errs = append(errs, fmt.Errorf("mixins[%d]: %s mixin already written to the same output directory by mixins[%d]", i, mixin.Type, j))
+				break
+			}
+			outputs[key] = i
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings of the mixin and returns all the errors found.
+func (m Mixin) Validate() error {
+	return errors.Join(m.validate()...)
+}
+
+func (m Mixin) validate() []error {
+	var errs []error
+	if !slices.Contains(MixinTypes, m.Type) {
+		errs = append(errs, fmt.Errorf("type: must be one of %s, got %q", strings.Join(MixinTypes, ", "), m.Type))
+	}
+	if slices.Contains(m.Datasources, "") {
+		errs = append(errs, errors.New("datasources: empty datasource name"))
+	}
+	if m.Datasource != "" && len(m.Datasources) > 0 && !slices.Contains(m.Datasources, m.Datasource) {
+		errs = append(errs, fmt.Errorf("datasource: must be one of the datasources, got %q", m.Datasource))
+	}
+	if m.ClusterLabelName != "" && !model.LabelName(m.ClusterLabelName).IsValidLegacy() {
+		errs = append(errs, fmt.Errorf("clusterLabelName: invalid label name %q", m.ClusterLabelName))
+	}
+	if m.Version != "" {
+		if m.Type != PrometheusMixin {
+			errs = append(errs, fmt.Errorf("version: only supported by the %s mixin", PrometheusMixin))
+		} else if _, err := prometheus.ParseVersion(m.Version); err != nil {
+			errs = append(errs, fmt.Errorf("version: %w", err))
+		}
+	}
+	scopeLabels := map[string]bool{m.ClusterLabelName: true}
+	for _, label := range m.ScopeLabels {
+		if !model.LabelName(label).IsValidLegacy() {
+			errs = append(errs, fmt.Errorf("scopeLabels: invalid label name %q", label))
+		} else if scopeLabels[label] {
+			errs = append(errs, fmt.Errorf("scopeLabels: label %q listed twice or already the cluster label", label))
+		}
+		scopeLabels[label] = true
+	}
+	if _, err := m.LabelMatchers(); err != nil {
+		errs = append(errs, fmt.Errorf("selector: %w", err))
+	}
+	if m.RateInterval != "" && !strings.HasPrefix(m.RateInterval, "$") {
+		if _, err := model.ParseDuration(m.RateInterval); err != nil {
+			errs = append(errs, fmt.Errorf("rateInterval: %w", err))
+		}
+	}
+	for name := range m.ExtraLabels {
+		if !model.LabelName(name).IsValidLegacy() {
+			errs = append(errs, fmt.Errorf("extraLabels: invalid label name %q", name))
+		}
+	}
+	return errs
+}
+
+// LabelMatchers parses the selector of the mixin, or its default selector when not set.
+func (m Mixin) LabelMatchers() ([]promql.LabelMatcher, error) {
+	selector := DefaultSelectors[m.Type]
+	if m.Selector != nil {
+		selector = *m.Selector
+	}
+	return promql.ParseSelector(selector)
+}
+
+// DashboardOptions returns the options of the dashboard builders of the mixin.
+func (m Mixin) DashboardOptions() (dashboards.Options, error) {
+	selector, err := m.LabelMatchers()
+	if err != nil {
+		return dashboards.Options{}, err
+	}
+	project := m.Project
+	if project == "" {
+		project = "default"
+	}
+	return dashboards.Options{
+		Project:          project,
+		Datasource:       m.Datasource,
+		Datasources:      m.Datasources,
+		ClusterLabelName: m.ClusterLabelName,
+		ScopeLabels:      m.ScopeLabels,
+		Version:          m.Version,
+		RateInterval:     m.RateInterval,
+		Selector:         selector,
+	}, nil
+}
+
+// RuleOptions returns the options of the rule builders of the mixin.
+func (m Mixin) RuleOptions() (rules.Options, error) {
+	selector, err := m.LabelMatchers()
+	if err != nil {
+		return rules.Options{}, err
+	}
+	return rules.Options{
+		Selector:    selector,
+		ExtraLabels: m.ExtraLabels,
+		Version:     m.Version,
+	}, nil
+}
+
+// RulesDir returns the directory the rules of the mixin are written to, the default one when the
+// mixin sets neither its rules output directory nor its output directory.
+func (m Mixin) RulesDir(defaultDir string) string {
+	if m.RulesOutputDir != "" {
+		return m.RulesOutputDir
+	}
+	if m.OutputDir != "" {
+		return filepath.Join(m.OutputDir, "rules")
+	}
+	return defaultDir
+}
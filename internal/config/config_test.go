@@ -0,0 +1,176 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	yamlPath := writeConfig(t, "mixins.yaml", `
+mixins:
+  - type: node-exporter
+    project: production
+    clusterLabelName: cluster
+    scopeLabels: [namespace]
+    selector: job=~"node.*"
+    rateInterval: 1m
+    extraLabels:
+      tier: 1
+    outputDir: dist/production
+    rulesOutputDir:
+  - type: prometheus
+    version: 2.50
+`)
+	jsonPath := writeConfig(t, "mixins.json", `{
+  "mixins": [
+    {
+      "type": "node-exporter",
+      "project": "production",
+      "clusterLabelName": "cluster",
+      "scopeLabels": ["namespace"],
+      "selector": "job=~\"node.*\"",
+      "rateInterval": "1m",
+      "extraLabels": {"tier": "1"},
+      "outputDir": "dist/production"
+    },
+    {"type": "prometheus", "version": "2.50"}
+  ]
+}`)
+
+	fromYAML, err := Load(yamlPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := Load(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("expected the YAML and JSON files to give the same configuration, got %+v and %+v", fromYAML, fromJSON)
+	}
+
+	options, err := fromYAML.Mixins[0].DashboardOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options.Selector) != 1 || options.Selector[0].Type != "=~" || options.Selector[0].Value != "node.*" {
+		t.Errorf("unexpected selector %+v", options.Selector)
+	}
+	if dir := fromYAML.Mixins[0].RulesDir("dist/rules"); dir != filepath.Join("dist", "production", "rules") {
+		t.Errorf("expected the rules in the output directory, got %q", dir)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unknown mixin",
+			file:    "mixins.yaml",
+			content: "mixins:\n  - type: blackbox\n",
+			want:    "/mixins/0/type",
+		},
+		{
+			name:    "unknown field",
+			file:    "mixins.json",
+			content: `{"mixins": [{"type": "prometheus", "datasourceName": "thanos"}]}`,
+			want:    "datasourceName",
+		},
+		{
+			name:    "no mixin",
+			file:    "mixins.yaml",
+			content: "mixins: []\n",
+			want:    "/mixins",
+		},
+		{
+			name:    "empty file",
+			file:    "mixins.yaml",
+			content: "",
+			want:    "invalid configuration",
+		},
+		{
+			name:    "bad selector",
+			file:    "mixins.yaml",
+			content: "mixins:\n  - type: prometheus\n    selector: job=\n",
+			want:    "mixins[0].selector",
+		},
+		{
+			name:    "bad rate window",
+			file:    "mixins.json",
+			content: `{"mixins": [{"type": "alertmanager", "rateInterval": "5 minutes"}]}`,
+			want:    "/mixins/0/rateInterval",
+		},
+		{
+			name:    "invalid label name",
+			file:    "mixins.yaml",
+			content: "mixins:\n  - type: node-exporter\n    scopeLabels: [k8s-namespace]\n",
+			want:    "/mixins/0/scopeLabels/0",
+		},
+		{
+			name:    "version of another mixin",
+			file:    "mixins.yaml",
+			content: "mixins:\n  - type: node-exporter\n    version: 1.8\n",
+			want:    "mixins[0].version",
+		},
+		{
+			name:    "same output directory",
+			file:    "mixins.yaml",
+			content: "mixins:\n  - type: node-exporter\n  - type: node-exporter\n    project: staging\n",
+			want:    "mixins[1]: node-exporter mixin already written",
+		},
+		{
+			name:    "invalid YAML",
+			file:    "mixins.yaml",
+			content: "mixins: [",
+			want:    "unable to decode",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tc.file, tc.content))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected an error about %s, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// The configurations built from the flags are not checked against the schema.
+	c := Config{Mixins: []Mixin{
+		{Type: "blackbox"},
+		{Type: PrometheusMixin, RateInterval: "5 minutes"},
+		{Type: NodeExporterMixin, Selector: new(string), ScopeLabels: []string{"cluster"}, ClusterLabelName: "cluster"},
+	}}
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"mixins[0].type", "mixins[1].rateInterval", "mixins[2].scopeLabels"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected an error about %s, got %v", want, err)
+		}
+	}
+
+	if err := (Config{Mixins: []Mixin{{Type: AlertmanagerMixin, RateInterval: "$__rate_interval"}}}).Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
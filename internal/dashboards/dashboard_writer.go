@@ -1,10 +1,20 @@
 package dashboards
 
-import "github.com/perses/perses/go-sdk/dashboard"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
+	"github.com/perses/perses/go-sdk/dashboard"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
 
 type DashboardWriter struct {
 	dashboardResults []DashboardResult
 	executor         Exec
+	resources        []string
 }
 
 type DashboardResult struct {
@@ -12,12 +22,27 @@ type DashboardResult struct {
 	err     error
 }
 
-func NewDashboardWriter() *DashboardWriter {
+func NewDashboardWriter(output Output) *DashboardWriter {
 	return &DashboardWriter{
-		executor: NewExec(),
+		executor: NewExec(output),
 	}
 }
 
+// SetOutputDir overrides the output directory of the writer, such as the one of a mixin.
+func (w *DashboardWriter) SetOutputDir(outputDir string) {
+	w.executor.outputDir = outputDir
+}
+
+// SetClient applies the dashboards to the Perses API of the client instead of writing them to files.
+func (w *DashboardWriter) SetClient(client *perses.Client) {
+	w.executor.client = client
+}
+
+// OutputDir returns the directory the dashboards are written to.
+func (w *DashboardWriter) OutputDir() string {
+	return w.executor.outputDir
+}
+
 func (w *DashboardWriter) Add(builder dashboard.Builder, err error) {
 	w.dashboardResults = append(w.dashboardResults, DashboardResult{
 		builder: builder,
@@ -25,8 +50,61 @@ func (w *DashboardWriter) Add(builder dashboard.Builder, err error) {
 	})
 }
 
-func (w *DashboardWriter) Write() {
+// Write writes every result, carrying on after a failure, and returns the errors of all the failed ones.
+func (w *DashboardWriter) Write() error {
+	var errs []error
 	for _, result := range w.dashboardResults {
-		w.executor.BuildDashboard(result.builder, result.err)
+		path, err := w.executor.BuildDashboard(result.builder, result.err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if w.executor.client == nil && w.executor.outputFormat == OperatorOutput {
+			w.resources = append(w.resources, path)
+		}
 	}
+	return errors.Join(errs...)
+}
+
+// Diff compares every result with the deployed dashboard, carrying on after a failure, and prints the changes
+// of the dashboards that drifted. It returns the number of dashboards that drifted and the errors of all the
+// failed ones.
+func (w *DashboardWriter) Diff(out io.Writer) (int, error) {
+	var errs []error
+	drifted := 0
+	for _, result := range w.dashboardResults {
+		changes, err := w.executor.DiffDashboard(result.builder, result.err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		drifted++
+		fmt.Fprintf(out, "dashboard %q:\n", result.builder.Dashboard.Metadata.Name)
+		for _, change := range changes {
+			fmt.Fprintf(out, "  %s\n", strings.ReplaceAll(change.String(), "\n", "\n  "))
+		}
+	}
+	return drifted, errors.Join(errs...)
+}
+
+// Dashboards returns the dashboards built without error, along with the errors of all the failed builders.
+func (w *DashboardWriter) Dashboards() ([]v1.Dashboard, error) {
+	var dashboards []v1.Dashboard
+	var errs []error
+	for _, result := range w.dashboardResults {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("dashboard %q: %w", result.builder.Dashboard.Metadata.Name, result.err))
+			continue
+		}
+		dashboards = append(dashboards, result.builder.Dashboard)
+	}
+	return dashboards, errors.Join(errs...)
+}
+
+// Resources returns the paths of the PersesDashboard custom resources written so far.
+func (w *DashboardWriter) Resources() []string {
+	return w.resources
 }
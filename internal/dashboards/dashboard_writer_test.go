@@ -0,0 +1,40 @@
+package dashboards_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+	"github.com/perses/perses/go-sdk/dashboard"
+)
+
+func TestDashboardWriterErrors(t *testing.T) {
+	// A directory cannot be created under a file, whatever the permissions of the user running the tests.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writer := dashboards.NewDashboardWriter(dashboards.Output{Format: dashboards.YAMLOutput, Dir: filepath.Join(file, "dashboards")})
+
+	names := []string{"node-exporter-nodes", "prometheus-overview", "alertmanager-overview"}
+	for _, name := range names {
+		writer.Add(dashboard.New(name))
+	}
+	writer.Add(dashboard.Builder{}, errors.New("invalid panel"))
+
+	err := writer.Write()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errs := err.(interface{ Unwrap() []error }).Unwrap(); len(errs) != len(names)+1 {
+		t.Errorf("expected an error per dashboard, got %d: %v", len(errs), err)
+	}
+	for _, want := range append(names, "invalid panel") {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in the error, got %q", want, err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package dashboardstest provides the golden file test helpers of the dashboard builders.
+package dashboardstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/lint"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	"github.com/perses/perses/go-sdk/dashboard"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the dashboards instead of comparing them")
+
+// Case is a set of builder options tested against its own golden file.
+type Case struct {
+	Name    string
+	Options dashboards.Options
+}
+
+// ClusterCases returns the options without a cluster label, then with a cluster label named "cluster", then
+// with the cluster label and a namespace scope label.
+func ClusterCases(options dashboards.Options) []Case {
+	withCluster := options
+	withCluster.ClusterLabelName = "cluster"
+	withScopes := withCluster
+	withScopes.ScopeLabels = []string{"namespace"}
+	return []Case{
+		{Name: "default", Options: options},
+		{Name: "cluster", Options: withCluster},
+		{Name: "scopes", Options: withScopes},
+	}
+}
+
+// AssertGolden checks the references to the variables of the dashboard of a builder, see lint.LintVariables,
+// then renders the dashboard to canonical JSON, with sorted keys and indented, and compares it with the golden
+// file testdata/<name>.json of the test package. With -update, the golden file is written instead:
+//
+//	go test ./pkg/dashboards/*/ -update
+func AssertGolden(t testing.TB, name string, builder dashboard.Builder, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unable to build dashboard: %v", err)
+	}
+
+	issues, err := lint.LintVariables(builder.Dashboard)
+	if err != nil {
+		t.Fatalf("unable to lint dashboard variables: %v", err)
+	}
+	for _, issue := range issues {
+		t.Error(issue)
+	}
+
+	got, err := CanonicalJSON(builder)
+	if err != nil {
+		t.Fatalf("unable to render dashboard: %v", err)
+	}
+	AssertGoldenFile(t, filepath.Join("testdata", name+".json"), got)
+}
+
+// AssertGoldenFile compares the rendered dashboard with the golden file, or writes the golden file with -update.
+func AssertGoldenFile(t testing.TB, path string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read golden file, run the tests with -update to create it: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("dashboard differs from %s, run the tests with -update if the change is expected:\n%s", path, lineDiff(string(want), string(got)))
+	}
+}
+
+// CanonicalJSON renders the dashboard of a builder to JSON with sorted keys, indented and ending with a newline,
+// so that the same dashboard always renders the same way whatever the plugin builders it was made with.
+func CanonicalJSON(builder dashboard.Builder) ([]byte, error) {
+	data, err := json.Marshal(builder.Dashboard)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// lineDiff returns the lines of the golden file that differ from the rendered dashboard, along with their
+// line number. It is not a minimal diff, only a pointer to the first changes.
+func lineDiff(want string, got string) string {
+	wantLines := bytes.Split([]byte(want), []byte("\n"))
+	gotLines := bytes.Split([]byte(got), []byte("\n"))
+
+	var buf bytes.Buffer
+	shown := 0
+	for i := 0; i < max(len(wantLines), len(gotLines)) && shown < 10; i++ {
+		var wantLine, gotLine []byte
+		if i < len(wantLines) {
+			wantLine = wantLines[i]
+		}
+		if i < len(gotLines) {
+			gotLine = gotLines[i]
+		}
+		if bytes.Equal(wantLine, gotLine) {
+			continue
+		}
+		shown++
+		fmt.Fprintf(&buf, "line %d:\n- %s\n+ %s\n", i+1, wantLine, gotLine)
+	}
+	return buf.String()
+}
@@ -1,76 +1,206 @@
 package dashboards
 
 import (
+	"context"
 	"encoding/json"
-	"flag"
+	"errors"
 	"fmt"
-	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/nicolastakashi/community-perses-dashboards/internal/grafana"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
 	"github.com/perses/perses/go-sdk/dashboard"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
 	"gopkg.in/yaml.v3"
 )
 
 const (
-	JSONOutput = "json"
-	YAMLOutput = "yaml"
+	JSONOutput     = "json"
+	YAMLOutput     = "yaml"
+	OperatorOutput = "operator"
+	GrafanaOutput  = "grafana"
 )
 
-func init() {
-	flag.String("output", YAMLOutput, "output format of the exec")
-	flag.String("output-dir", "./dist", "output directory of the exec")
+// PersesDashboard is the Perses operator custom resource wrapping a dashboard.
+type PersesDashboard struct {
+	APIVersion string                  `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string                  `json:"kind" yaml:"kind"`
+	Metadata   PersesDashboardMetadata `json:"metadata" yaml:"metadata"`
+	Spec       v1.DashboardSpec        `json:"spec" yaml:"spec"`
 }
 
-func executeDashboardBuilder(builder dashboard.Builder, outputFormat string, outputDir string, errWriter io.Writer) {
+type PersesDashboardMetadata struct {
+	Name        string            `json:"name" yaml:"name"`
+	Namespace   string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// Output tells how the dashboards are written: in which format, to which directory and, for the
+// PersesDashboard custom resources, with which metadata. The metadata name is the dashboard one.
+type Output struct {
+	Format   string
+	Dir      string
+	Metadata PersesDashboardMetadata
+}
+
+// KeyValueFlag is a flag holding comma separated key=value pairs. Repeating the flag adds more pairs.
+type KeyValueFlag map[string]string
+
+func (f KeyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for key, value := range f {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f KeyValueFlag) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("invalid key=value pair %q", pair)
+		}
+		f[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+func executeDashboardBuilder(builder dashboard.Builder, outputFormat string, outputDir string, metadata PersesDashboardMetadata) (string, error) {
 	var err error
 	var output []byte
+	extension := outputFormat
 	if outputFormat == YAMLOutput {
 		output, err = yaml.Marshal(builder.Dashboard)
 	} else if outputFormat == JSONOutput {
 		output, err = json.Marshal(builder.Dashboard)
+	} else if outputFormat == OperatorOutput {
+		metadata.Name = builder.Dashboard.Metadata.Name
+		output, err = yaml.Marshal(PersesDashboard{
+			APIVersion: "perses.dev/v1alpha1",
+			Kind:       "PersesDashboard",
+			Metadata:   metadata,
+			Spec:       builder.Dashboard.Spec,
+		})
+		extension = YAMLOutput
+	} else if outputFormat == GrafanaOutput {
+		var grafanaDashboard grafana.Dashboard
+		if grafanaDashboard, err = grafana.Convert(builder.Dashboard); err == nil {
+			output, err = json.MarshalIndent(grafanaDashboard, "", "  ")
+		}
+		extension = JSONOutput
 	} else {
-		err = fmt.Errorf("--output must be %q or %q", JSONOutput, YAMLOutput)
+		err = fmt.Errorf("--output must be %q, %q, %q or %q", JSONOutput, YAMLOutput, OperatorOutput, GrafanaOutput)
 	}
-
 	if err != nil {
-		fmt.Fprint(errWriter, err)
-		os.Exit(-1)
+		return "", err
 	}
 
-	// create output directory if not exists
-	_, err = os.Stat(outputDir)
-	if err != nil && !os.IsNotExist(err) {
-		fmt.Fprint(errWriter, err)
-		os.Exit(-1)
+	if err = os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return "", err
 	}
 
-	if err != nil && os.IsNotExist(err) {
-		_ = os.MkdirAll(outputDir, os.ModePerm)
-	}
-
-	_ = os.WriteFile(fmt.Sprintf("%s/%s.%s", outputDir, builder.Dashboard.Metadata.Name, outputFormat), output, os.ModePerm)
+	path := filepath.Join(outputDir, fmt.Sprintf("%s.%s", builder.Dashboard.Metadata.Name, extension))
+	return path, os.WriteFile(path, output, os.ModePerm)
 }
 
-func NewExec() Exec {
-	output := flag.Lookup("output").Value.String()
-	outputDir := flag.Lookup("output-dir").Value.String()
-
+func NewExec(output Output) Exec {
 	return Exec{
-		outputFormat: output,
-		outputDir:    outputDir,
+		outputFormat:     output.Format,
+		outputDir:        output.Dir,
+		operatorMetadata: output.Metadata,
 	}
 }
 
 type Exec struct {
-	outputFormat string
-	outputDir    string
+	outputFormat     string
+	outputDir        string
+	operatorMetadata PersesDashboardMetadata
+	client           *perses.Client
+}
+
+// BuildDashboard writes the result of a dashboard builder to the output directory and returns the path of
+// the written file, or applies it to the Perses API when a client is set. It returns the builder error or
+// the marshal, write or API error, naming the dashboard.
+func (b *Exec) BuildDashboard(builder dashboard.Builder, err error) (string, error) {
+	var path string
+	if err == nil {
+		if b.client != nil {
+			err = b.client.ApplyDashboard(context.Background(), builder.Dashboard)
+		} else {
+			path, err = executeDashboardBuilder(builder, b.outputFormat, b.outputDir, b.operatorMetadata)
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("dashboard %q: %w", builder.Dashboard.Metadata.Name, err)
+	}
+	return path, nil
+}
+
+// DiffDashboard compares the result of a dashboard builder with the dashboard deployed to the Perses API when
+// a client is set, or with the file of the output directory otherwise. A dashboard found in neither place is
+// reported as added.
+func (b *Exec) DiffDashboard(builder dashboard.Builder, err error) ([]perses.Change, error) {
+	var changes []perses.Change
+	if err == nil {
+		var current v1.Dashboard
+		var found bool
+		if b.client != nil {
+			current, found, err = b.client.GetDashboard(context.Background(), builder.Dashboard.Metadata.Project, builder.Dashboard.Metadata.Name)
+		} else {
+			current, found, err = readDashboard(builder.Dashboard, b.outputFormat, b.outputDir)
+		}
+		if err == nil && found {
+			changes, err = perses.Diff(current, builder.Dashboard)
+		} else if err == nil {
+			changes = []perses.Change{{Type: perses.Added, Object: "dashboard"}}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dashboard %q: %w", builder.Dashboard.Metadata.Name, err)
+	}
+	return changes, nil
 }
 
-// BuildDashboard is a helper to print the result of a dashboard builder in stdout and errors to stderr
-func (b *Exec) BuildDashboard(builder dashboard.Builder, err error) {
+// readDashboard reads the file previously written for the dashboard with the output format, and returns
+// false when there is none.
+func readDashboard(dashboard v1.Dashboard, outputFormat string, outputDir string) (v1.Dashboard, bool, error) {
+	extension := outputFormat
+	switch outputFormat {
+	case JSONOutput, YAMLOutput:
+	case OperatorOutput:
+		extension = YAMLOutput
+	default:
+		return v1.Dashboard{}, false, fmt.Errorf("cannot diff the dashboards written with --output=%s", outputFormat)
+	}
+
+	path := filepath.Join(outputDir, fmt.Sprintf("%s.%s", dashboard.Metadata.Name, extension))
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return v1.Dashboard{}, false, nil
+	}
+	if err != nil {
+		return v1.Dashboard{}, false, err
+	}
+
+	var current v1.Dashboard
+	switch outputFormat {
+	case JSONOutput:
+		err = json.Unmarshal(data, &current)
+	case YAMLOutput:
+		err = yaml.Unmarshal(data, &current)
+	case OperatorOutput:
+		var resource PersesDashboard
+		err = yaml.Unmarshal(data, &resource)
+		current = v1.Dashboard{Kind: dashboard.Kind, Metadata: dashboard.Metadata, Spec: resource.Spec}
+	}
 	if err != nil {
-		fmt.Fprint(os.Stderr, err)
-		os.Exit(-1)
+		return v1.Dashboard{}, false, fmt.Errorf("unable to decode %q: %w", path, err)
 	}
-	executeDashboardBuilder(builder, b.outputFormat, b.outputDir, os.Stdout)
+	return current, true, nil
 }
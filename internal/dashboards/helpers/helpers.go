@@ -0,0 +1,213 @@
+// Package helpers holds the building blocks of the public dashboards and panels, such as the query and
+// variable options injecting the label matchers, the rate interval variable and the scope variables. It has
+// no dependency on the code of the command.
+package helpers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	dashboardsSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	promqlSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/perses/perses/go-sdk/dashboard"
+	"github.com/perses/perses/go-sdk/panel"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// RateIntervalVariable is the name of the dashboard variable holding the range of the rates in the panels.
+	RateIntervalVariable = dashboardsSdk.RateIntervalVariable
+	// DefaultRateInterval lets Perses pick a range suited to the scrape interval and the time range.
+	DefaultRateInterval = dashboardsSdk.DefaultRateInterval
+)
+
+func AddVariableDatasource(datasourceName string) labelValuesVar.Option {
+	if datasourceName == "" {
+		return func(plugin *labelValuesVar.Builder) error {
+			return nil
+		}
+	}
+	return labelValuesVar.Datasource(datasourceName)
+}
+
+func AddQueryDataSource(datasourceName string) query.Option {
+	if datasourceName == "" {
+		return func(plugin *query.Builder) error {
+			return nil
+		}
+	}
+	return query.Datasource(datasourceName)
+}
+
+// AddPrometheusQuery adds a PromQL query to a panel after injecting the label matchers in the expression
+// and replacing the range of every rate with the rate interval variable.
+// It fails with the panel title and the expression when the expression cannot be parsed.
+func AddPrometheusQuery(expr string, labelMatchers []promql.LabelMatcher, options ...query.Option) panel.Option {
+	return func(builder *panel.Builder) error {
+		q, err := promql.SetLabelMatchers(expr, labelMatchers)
+		if err == nil {
+			q, err = promql.SetRangeInterval(q, "$"+RateIntervalVariable)
+		}
+		if err != nil {
+			return fmt.Errorf("panel %q: %w", builder.Spec.Display.Name, err)
+		}
+		return panel.AddQuery(query.PromQL(q, options...))(builder)
+	}
+}
+
+// AddQuery adds the query of a PromQL builder to a panel, as AddPrometheusQuery does with the expressions
+// written as strings: the label matchers are added to its vector selectors and the rate interval variable is
+// the range of its rates.
+func AddQuery(q promqlSdk.Query, labelMatchers []promql.LabelMatcher, options ...query.Option) panel.Option {
+	return func(builder *panel.Builder) error {
+		expr, err := q.Where(labelMatchers...).WithRange("$" + RateIntervalVariable).Build()
+		if err != nil {
+			return fmt.Errorf("panel %q: %w", builder.Spec.Display.Name, err)
+		}
+		return panel.AddQuery(query.PromQL(expr, options...))(builder)
+	}
+}
+
+// AddVariableMatcher sets the series matcher of a label values variable after injecting the label matchers in it.
+// It fails with the variable label name and the matcher when the matcher cannot be parsed.
+func AddVariableMatcher(matcher string, labelMatchers []promql.LabelMatcher) labelValuesVar.Option {
+	return func(builder *labelValuesVar.Builder) error {
+		m, err := promql.SetLabelMatchers(matcher, labelMatchers)
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", builder.LabelName, err)
+		}
+		return labelValuesVar.Matchers(m)(builder)
+	}
+}
+
+// AddScopeVariables adds a variable per scope, offering the values of its label in the series of the matcher.
+// Each variable is filtered by the label matchers and by the variables of the previous scopes.
+func AddScopeVariables(datasource string, scopes []dashboardsSdk.Scope, matcher string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return func(builder *dashboard.Builder) error {
+		variableLabelMatchers := slices.Clone(labelMatchers)
+		for _, scope := range scopes {
+			err := dashboard.AddVariable(scope.Variable,
+				listVar.List(
+					labelValuesVar.PrometheusLabelValues(scope.Label,
+						AddVariableMatcher(matcher, variableLabelMatchers),
+						AddVariableDatasource(datasource),
+					),
+					listVar.DisplayName(scope.Label),
+					listVar.AllowMultiple(true),
+					listVar.AllowAllValue(true),
+				),
+			)(builder)
+			if err != nil {
+				return err
+			}
+			variableLabelMatchers = append(variableLabelMatchers, GetScopeLabelMatchers([]dashboardsSdk.Scope{scope})...)
+		}
+		return nil
+	}
+}
+
+// AddRateIntervalVariable adds the variable holding the range of the rates in the panels.
+// The rate interval is the default value of the variable, $__rate_interval when empty.
+// It fails when the rate interval is neither a Prometheus duration nor a variable.
+func AddRateIntervalVariable(rateInterval string) dashboard.Option {
+	return func(builder *dashboard.Builder) error {
+		if rateInterval == "" {
+			rateInterval = DefaultRateInterval
+		}
+		if !strings.HasPrefix(rateInterval, "$") {
+			if _, err := model.ParseDuration(rateInterval); err != nil {
+				return fmt.Errorf("invalid rate interval %q: %w", rateInterval, err)
+			}
+		}
+		values := []string{DefaultRateInterval, "1m", "5m", "15m", "1h"}
+		if !slices.Contains(values, rateInterval) {
+			values = append(values, rateInterval)
+		}
+		return dashboard.AddVariable(RateIntervalVariable,
+			listVar.List(
+				dashboardsSdk.StaticList(values...),
+				listVar.DefaultValue(rateInterval),
+				listVar.DisplayName("rate interval"),
+			),
+		)(builder)
+	}
+}
+
+// GetSelectorLabelMatchers returns the label matchers of a mixin selector without the matchers on the excluded
+// labels. Dashboards exclude the labels filtered by their variables from the panel queries, since the selector
+// already restricts the values of those variables.
+func GetSelectorLabelMatchers(selector []promql.LabelMatcher, excludedLabels ...string) []promql.LabelMatcher {
+	labelMatchers := make([]promql.LabelMatcher, 0, len(selector))
+	for _, l := range selector {
+		if !slices.Contains(excludedLabels, l.Name) {
+			labelMatchers = append(labelMatchers, l)
+		}
+	}
+	return labelMatchers
+}
+
+// GetScopeLabelMatchers returns the label matchers filtering the series on the values of the scope variables.
+func GetScopeLabelMatchers(scopes []dashboardsSdk.Scope) []promql.LabelMatcher {
+	labelMatchers := make([]promql.LabelMatcher, 0, len(scopes))
+	for _, scope := range scopes {
+		labelMatchers = append(labelMatchers, promql.LabelMatcher{
+			Name:  scope.Label,
+			Value: "$" + scope.Variable,
+			Type:  "=~",
+		})
+	}
+	return labelMatchers
+}
+
+// ScopeQuery keeps the scope labels in the aggregations and vector matchings of the query, and prefixes its
+// series name format with them when the format shows labels, so that the series of several clusters are
+// neither merged nor mistaken for one another. Formats without labels, usually the name of a total, are kept.
+func ScopeQuery(spec *query.PluginSpec, scopeLabels []string) error {
+	if len(scopeLabels) == 0 {
+		return nil
+	}
+	q, err := promql.SetGroupingLabels(spec.Query, scopeLabels)
+	if err != nil {
+		return err
+	}
+	spec.Query = q
+	if strings.Contains(spec.SeriesNameFormat, "{{") {
+		var prefix strings.Builder
+		for _, label := range scopeLabels {
+			if !strings.Contains(spec.SeriesNameFormat, "{{"+label+"}}") {
+				prefix.WriteString("{{" + label + "}} - ")
+			}
+		}
+		spec.SeriesNameFormat = prefix.String() + spec.SeriesNameFormat
+	}
+	return nil
+}
+
+// ScopePanels applies ScopeQuery with the labels of the scopes to every PromQL query of the dashboard panels.
+// Dashboards add it after their panel groups.
+func ScopePanels(scopes []dashboardsSdk.Scope) dashboard.Option {
+	scopeLabels := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scopeLabels = append(scopeLabels, scope.Label)
+	}
+	return func(builder *dashboard.Builder) error {
+		for _, p := range builder.Dashboard.Spec.Panels {
+			for i, q := range p.Spec.Queries {
+				plugin, ok := q.Spec.Plugin.Spec.(query.Builder)
+				if !ok {
+					continue
+				}
+				if err := ScopeQuery(&plugin.PluginSpec, scopeLabels); err != nil {
+					return fmt.Errorf("panel %q: %w", p.Spec.Display.Name, err)
+				}
+				p.Spec.Queries[i].Spec.Plugin.Spec = plugin
+			}
+		}
+		return nil
+	}
+}
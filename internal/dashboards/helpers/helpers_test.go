@@ -0,0 +1,77 @@
+package helpers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func TestQueryErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		option        dashboard.Option
+		wantInMessage []string
+	}{
+		{
+			name: "unparsable panel expression",
+			option: dashboard.AddPanelGroup("CPU",
+				panelgroup.AddPanel("CPU Usage",
+					helpers.AddPrometheusQuery("sum(rate(node_cpu_seconds_total[5m])", nil),
+				),
+			),
+			wantInMessage: []string{`panel "CPU Usage"`, `"sum(rate(node_cpu_seconds_total[5m])"`},
+		},
+		{
+			name: "unknown panel match type",
+			option: dashboard.AddPanelGroup("CPU",
+				panelgroup.AddPanel("CPU Usage",
+					helpers.AddPrometheusQuery("node_load1", []promql.LabelMatcher{{Name: "job", Type: "==", Value: "node"}}),
+				),
+			),
+			wantInMessage: []string{`panel "CPU Usage"`, `"node_load1"`, `unknown match type "=="`},
+		},
+		{
+			name: "unknown panel match type with an empty value",
+			option: dashboard.AddPanelGroup("CPU",
+				panelgroup.AddPanel("CPU Usage",
+					helpers.AddPrometheusQuery("node_load1", []promql.LabelMatcher{{Name: "job", Type: "~"}}),
+				),
+			),
+			wantInMessage: []string{`panel "CPU Usage"`, `"node_load1"`, `unknown match type "~"`},
+		},
+		{
+			name: "unparsable variable matcher",
+			option: dashboard.AddVariable("job",
+				listVar.List(labelValuesVar.PrometheusLabelValues("job", helpers.AddVariableMatcher("up{", nil))),
+			),
+			wantInMessage: []string{`variable "job"`, `"up{"`},
+		},
+		{
+			name: "unknown variable match type",
+			option: dashboard.AddVariable("job",
+				listVar.List(labelValuesVar.PrometheusLabelValues("job",
+					helpers.AddVariableMatcher("up", []promql.LabelMatcher{{Name: "namespace", Type: "=!", Value: "monitoring"}}),
+				)),
+			),
+			wantInMessage: []string{`variable "job"`, `"up"`, `unknown match type "=!"`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := dashboard.New("test", tc.option)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tc.wantInMessage {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected %s in the error, got %q", want, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package dashboards
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/config"
+	dashboardsSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards/alertmanager"
+	nodeexporter "github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards/node_exporter"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards/prometheus"
+	"github.com/perses/perses/go-sdk/dashboard"
+)
+
+// Registration describes a dashboard a mixin provides.
+type Registration struct {
+	// Mixin is the mixin of the dashboard, such as prometheus or node-exporter.
+	Mixin string
+	// Name is the name of the built dashboard, the one the include and exclude patterns match.
+	Name        string
+	Description string
+	Tags        []string
+	Build       func(options ...dashboardsSdk.Option) (dashboard.Builder, error)
+}
+
+// registry lists the dashboards of every mixin. It lives with the CLI rather than in the mixin packages, so
+// that importing a dashboard package registers nothing.
+var registry = []Registration{
+	{
+		Mixin:       config.PrometheusMixin,
+		Name:        "prometheus-overview",
+		Description: "Target discovery, scrapes, storage and query statistics of Prometheus servers",
+		Tags:        []string{"prometheus"},
+		Build:       prometheus.Overview,
+	},
+	{
+		Mixin:       config.PrometheusMixin,
+		Name:        "prometheus-remote-write",
+		Description: "Remote write lag, throughput, shards and failed samples of Prometheus servers",
+		Tags:        []string{"prometheus", "remote-write"},
+		Build:       prometheus.RemoteWrite,
+	},
+	{
+		Mixin:       config.NodeExporterMixin,
+		Name:        "node-exporter-nodes",
+		Description: "CPU, load, memory, disk and network usage of a single node",
+		Tags:        []string{"node-exporter", "linux"},
+		Build:       nodeexporter.Nodes,
+	},
+	{
+		Mixin:       config.NodeExporterMixin,
+		Name:        "node-exporter-cluster-use-method",
+		Description: "Utilisation and saturation of the CPU, memory, network and disks of every node, following the USE method",
+		Tags:        []string{"node-exporter", "linux", "use-method"},
+		Build:       nodeexporter.ClusterUseMethod,
+	},
+	{
+		Mixin:       config.AlertmanagerMixin,
+		Name:        "alertmanager-overview",
+		Description: "Alerts received and notifications sent by Alertmanager, with their failures and latency per integration",
+		Tags:        []string{"alertmanager", "alerting"},
+		Build:       alertmanager.Overview,
+	},
+}
+
+// Registrations returns the dashboards of the mixin matching the filter, in registration order.
+func Registrations(mixin string, filter Filter) []Registration {
+	var registrations []Registration
+	for _, r := range registry {
+		if r.Mixin == mixin && filter.Match(r.Name) {
+			registrations = append(registrations, r)
+		}
+	}
+	return registrations
+}
+
+// Filter selects dashboards by name with path.Match patterns, such as node-exporter-*.
+type Filter struct {
+	// Include selects the dashboards matching any of the patterns, or every dashboard when empty.
+	Include Patterns
+	// Exclude leaves out the dashboards matching any of the patterns, even the included ones.
+	Exclude Patterns
+}
+
+// Match tells whether the filter selects the dashboard name.
+func (f Filter) Match(name string) bool {
+	return (len(f.Include) == 0 || f.Include.Match(name)) && !f.Exclude.Match(name)
+}
+
+// Patterns is a flag holding comma separated path.Match patterns. Repeating the flag adds more patterns.
+type Patterns []string
+
+func (p *Patterns) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(*p, ",")
+}
+
+func (p *Patterns) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return fmt.Errorf("invalid pattern %q", pattern)
+		}
+		*p = append(*p, pattern)
+	}
+	return nil
+}
+
+// Match tells whether the name matches any of the patterns.
+func (p Patterns) Match(name string) bool {
+	for _, pattern := range p {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
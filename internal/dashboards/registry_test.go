@@ -0,0 +1,60 @@
+package dashboards_test
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/config"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+)
+
+func TestRegistrations(t *testing.T) {
+	names := map[string]bool{}
+	for _, mixin := range config.MixinTypes {
+		registrations := dashboards.Registrations(mixin, dashboards.Filter{})
+		if len(registrations) == 0 {
+			t.Errorf("mixin %q registers no dashboard", mixin)
+		}
+		for _, registration := range registrations {
+			if names[registration.Name] {
+				t.Errorf("dashboard %q registered twice", registration.Name)
+			}
+			names[registration.Name] = true
+			builder, err := registration.Build()
+			if err != nil {
+				t.Fatalf("dashboard %q: %v", registration.Name, err)
+			}
+			if name := builder.Dashboard.Metadata.Name; name != registration.Name {
+				t.Errorf("dashboard registered as %q is named %q", registration.Name, name)
+			}
+			if registration.Description == "" || len(registration.Tags) == 0 {
+				t.Errorf("dashboard %q has no description or tags", registration.Name)
+			}
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var include, exclude dashboards.Patterns
+	if err := include.Set("node-exporter-*,prometheus-overview"); err != nil {
+		t.Fatal(err)
+	}
+	if err := exclude.Set("*-use-method"); err != nil {
+		t.Fatal(err)
+	}
+	filter := dashboards.Filter{Include: include, Exclude: exclude}
+
+	for name, want := range map[string]bool{
+		"node-exporter-nodes":              true,
+		"node-exporter-cluster-use-method": false,
+		"prometheus-overview":              true,
+		"prometheus-remote-write":          false,
+	} {
+		if got := filter.Match(name); got != want {
+			t.Errorf("Match(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if err := include.Set("node-[exporter"); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,611 @@
+package grafana
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
+	commonSdk "github.com/perses/perses/go-sdk/common"
+	"github.com/perses/perses/go-sdk/datasource"
+	gaugePanel "github.com/perses/perses/go-sdk/panel/gauge"
+	tablePanel "github.com/perses/perses/go-sdk/panel/table"
+	timeSeriesPanel "github.com/perses/perses/go-sdk/panel/time-series"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	promqlVar "github.com/perses/perses/go-sdk/prometheus/variable/promql"
+	staticListVar "github.com/perses/perses/go-sdk/variable/plugin/static-list"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/perses/perses/pkg/model/api/v1/variable"
+	"github.com/prometheus/common/model"
+)
+
+const staticListVariableKind = "StaticListVariable"
+
+// units maps the Perses units to the Grafana ones. Units missing from the map are left unset.
+var units = map[string]string{
+	string(commonSdk.MilliSecondsUnit):       "ms",
+	string(commonSdk.SecondsUnit):            "s",
+	string(commonSdk.MinutesUnit):            "m",
+	string(commonSdk.HoursUnit):              "h",
+	string(commonSdk.DaysUnit):               "d",
+	string(commonSdk.PercentUnit):            "percent",
+	string(commonSdk.PercentDecimalUnit):     "percentunit",
+	commonSdk.DecimalUnit:                    "short",
+	commonSdk.BytesUnit:                      "bytes",
+	string(commonSdk.BitsPerSecondsUnit):     "bps",
+	string(commonSdk.BytesPerSecondsUnit):    "Bps",
+	string(commonSdk.CountsPerSecondsUnit):   "cps",
+	string(commonSdk.EventsPerSecondsUnit):   "eps",
+	string(commonSdk.MessagesPerSecondsUnit): "mps",
+	string(commonSdk.OpsPerSecondsUnit):      "ops",
+	string(commonSdk.PacketsPerSecondsUnit):  "pps",
+	string(commonSdk.ReadsPerSecondsUnit):    "rps",
+	string(commonSdk.RecordsPerSecondsUnit):  "recps",
+	string(commonSdk.RequestsPerSecondsUnit): "reqps",
+	string(commonSdk.RowsPerSecondsUnit):     "rowsps",
+	string(commonSdk.WritesPerSecondsUnit):   "wps",
+}
+
+// calculations maps the Perses calculations to the Grafana reducers.
+var calculations = map[commonSdk.Calculation]string{
+	commonSdk.FirstCalculation:       "first",
+	commonSdk.LastCalculation:        "last",
+	commonSdk.FirstNumberCalculation: "firstNotNull",
+	commonSdk.LastNumberCalculation:  "lastNotNull",
+	commonSdk.MeanCalculation:        "mean",
+	commonSdk.SumCalculation:         "sum",
+	commonSdk.MinCalculation:         "min",
+	commonSdk.MaxCalculation:         "max",
+}
+
+// sorts maps the Perses variable sorts to the Grafana ones.
+var sorts = map[variable.Sort]int{
+	variable.SortNone:                            0,
+	variable.SortAlphabeticalAsc:                 1,
+	variable.SortAlphabeticalDesc:                2,
+	variable.SortNumericalAsc:                    3,
+	variable.SortNumericalDesc:                   4,
+	variable.SortAlphabeticalCaseInsensitiveAsc:  5,
+	variable.SortAlphabeticalCaseInsensitiveDesc: 6,
+}
+
+// columns maps the Perses names of the value and timestamp columns of a table to the Grafana ones.
+var columns = map[string]string{
+	"value":     "Value",
+	"timestamp": "Time",
+}
+
+// Convert converts a Perses dashboard to a Grafana dashboard. Every panel group becomes a row followed by
+// its panels. Time series, table and gauge panels are supported, along with the Prometheus label values,
+// PromQL and static list variables and the text variables; other plugins are reported as errors.
+// The panels and variables without datasource use the Prometheus datasource selected by a datasource variable.
+func Convert(persesDashboard v1.Dashboard) (Dashboard, error) {
+	persesDashboard, err := perses.Normalize(persesDashboard)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	spec := persesDashboard.Spec
+
+	result := Dashboard{
+		UID:           persesDashboard.Metadata.Name,
+		Title:         persesDashboard.Metadata.Name,
+		Tags:          []string{},
+		Editable:      true,
+		SchemaVersion: SchemaVersion,
+		Time:          TimeRange{From: "now-1h", To: "now"},
+		Panels:        []Panel{},
+	}
+	if spec.Display != nil {
+		if spec.Display.Name != "" {
+			result.Title = spec.Display.Name
+		}
+		result.Description = spec.Display.Description
+	}
+	if spec.Duration > 0 {
+		result.Time.From = "now-" + spec.Duration.String()
+	}
+	if spec.RefreshInterval > 0 {
+		result.Refresh = spec.RefreshInterval.String()
+	}
+
+	result.Templating.List = []Variable{{
+		Name:  DatasourceVariable,
+		Label: "Data source",
+		Type:  "datasource",
+		Query: "prometheus",
+	}}
+	for _, v := range spec.Variables {
+		if v.Spec.GetName() == DatasourceVariable {
+			// The dashboard lists its own datasources, which replace the default datasource variable.
+			grafanaVariable, err := convertDatasourceVariable(v)
+			if err != nil {
+				return Dashboard{}, fmt.Errorf("variable %q: %w", DatasourceVariable, err)
+			}
+			result.Templating.List[0] = grafanaVariable
+			continue
+		}
+		grafanaVariable, err := convertVariable(v)
+		if err != nil {
+			return Dashboard{}, fmt.Errorf("variable %q: %w", v.Spec.GetName(), err)
+		}
+		result.Templating.List = append(result.Templating.List, grafanaVariable)
+	}
+
+	id := 1
+	y := 0
+	for i, layout := range spec.Layouts {
+		grid, ok := layout.Spec.(*dashboard.GridLayoutSpec)
+		if !ok {
+			return Dashboard{}, fmt.Errorf("layout %d: unsupported layout %q", i, layout.Kind)
+		}
+
+		var row *Panel
+		if grid.Display != nil {
+			collapsed := grid.Display.Collapse != nil && !grid.Display.Collapse.Open
+			row = &Panel{
+				ID:        id,
+				Type:      "row",
+				Title:     grid.Display.Title,
+				GridPos:   GridPos{H: 1, W: 24, X: 0, Y: y},
+				Collapsed: &collapsed,
+				Panels:    []Panel{},
+			}
+			id++
+			y++
+		}
+
+		height := 0
+		var panels []Panel
+		for _, item := range grid.Items {
+			panel, ok := spec.Panels[strings.TrimPrefix(item.Content.Ref, "#/spec/panels/")]
+			if !ok {
+				return Dashboard{}, fmt.Errorf("layout %d: unknown panel %q", i, item.Content.Ref)
+			}
+			grafanaPanel, err := convertPanel(panel)
+			if err != nil {
+				return Dashboard{}, fmt.Errorf("panel %q: %w", panel.Spec.Display.Name, err)
+			}
+			grafanaPanel.ID = id
+			grafanaPanel.GridPos = GridPos{H: item.Height, W: item.Width, X: item.X, Y: y + item.Y}
+			panels = append(panels, grafanaPanel)
+			height = max(height, item.Y+item.Height)
+			id++
+		}
+		y += height
+
+		switch {
+		case row == nil:
+			result.Panels = append(result.Panels, panels...)
+		case *row.Collapsed:
+			row.Panels = append(row.Panels, panels...)
+			result.Panels = append(result.Panels, *row)
+		default:
+			result.Panels = append(result.Panels, *row)
+			result.Panels = append(result.Panels, panels...)
+		}
+	}
+	return result, nil
+}
+
+func convertPanel(panel *v1.Panel) (Panel, error) {
+	result := Panel{
+		Title:       panel.Spec.Display.Name,
+		Description: panel.Spec.Display.Description,
+	}
+
+	queries, err := perses.PanelQueries(panel)
+	if err != nil {
+		return Panel{}, err
+	}
+	for i, query := range queries {
+		result.Targets = append(result.Targets, Target{
+			RefID:        refID(i),
+			Datasource:   datasourceRef(query.Datasource),
+			Expr:         query.Query,
+			LegendFormat: query.SeriesNameFormat,
+			Range:        true,
+		})
+		if query.MinStep > 0 {
+			result.Targets[i].Interval = query.MinStep.String()
+		}
+	}
+	if len(result.Targets) > 0 {
+		result.Datasource = result.Targets[0].Datasource
+	}
+
+	plugin := panel.Spec.Plugin
+	switch plugin.Kind {
+	case timeSeriesPanel.PluginKind:
+		var spec timeSeriesPanel.PluginSpec
+		if err := perses.DecodePluginSpec(plugin, &spec); err != nil {
+			return Panel{}, err
+		}
+		convertTimeSeries(&result, spec)
+	case tablePanel.PluginKind:
+		var spec tablePanel.PluginSpec
+		if err := perses.DecodePluginSpec(plugin, &spec); err != nil {
+			return Panel{}, err
+		}
+		convertTable(&result, spec)
+	case gaugePanel.PluginKind:
+		var spec gaugePanel.PluginSpec
+		if err := perses.DecodePluginSpec(plugin, &spec); err != nil {
+			return Panel{}, err
+		}
+		if err := convertGauge(&result, spec); err != nil {
+			return Panel{}, err
+		}
+	default:
+		return Panel{}, fmt.Errorf("unsupported panel plugin %q", plugin.Kind)
+	}
+	return result, nil
+}
+
+func convertTimeSeries(panel *Panel, spec timeSeriesPanel.PluginSpec) {
+	panel.Type = "timeseries"
+	panel.FieldConfig = &FieldConfig{Overrides: []any{}}
+	defaults := &panel.FieldConfig.Defaults
+
+	legend := map[string]any{"showLegend": false, "displayMode": "list", "placement": "bottom", "calcs": []string{}}
+	if spec.Legend != nil {
+		legend["showLegend"] = true
+		if spec.Legend.Mode == timeSeriesPanel.TableMode {
+			legend["displayMode"] = "table"
+		}
+		if spec.Legend.Position == timeSeriesPanel.RightPosition {
+			legend["placement"] = "right"
+		}
+		calcs := []string{}
+		for _, value := range spec.Legend.Values {
+			if calc, ok := calculations[value]; ok {
+				calcs = append(calcs, calc)
+			}
+		}
+		legend["calcs"] = calcs
+	}
+	panel.Options = map[string]any{
+		"legend":  legend,
+		"tooltip": map[string]any{"mode": "multi", "sort": "none"},
+	}
+
+	custom := map[string]any{
+		"drawStyle":   "line",
+		"lineWidth":   1,
+		"fillOpacity": 0,
+		"showPoints":  "auto",
+		"spanNulls":   false,
+		"stacking":    map[string]any{"mode": "none"},
+	}
+	if visual := spec.Visual; visual != nil {
+		if visual.Display == timeSeriesPanel.BarDisplay {
+			custom["drawStyle"] = "bars"
+		}
+		if visual.LineWidth > 0 {
+			custom["lineWidth"] = visual.LineWidth
+		}
+		custom["fillOpacity"] = visual.AreaOpacity * 100
+		if visual.ShowPoints == timeSeriesPanel.AlwaysShowPoints {
+			custom["showPoints"] = "always"
+		}
+		if visual.PointRadius > 0 {
+			custom["pointSize"] = visual.PointRadius * 2
+		}
+		switch visual.Stack {
+		case timeSeriesPanel.AllStack:
+			custom["stacking"] = map[string]any{"mode": "normal"}
+		case timeSeriesPanel.PercentageStack:
+			custom["stacking"] = map[string]any{"mode": "percent"}
+		}
+		custom["spanNulls"] = visual.ConnectNulls
+	}
+	if yAxis := spec.YAxis; yAxis != nil {
+		setFormat(defaults, yAxis.Format)
+		if yAxis.Label != "" {
+			custom["axisLabel"] = yAxis.Label
+		}
+		if yAxis.Min != 0 {
+			defaults.Min = &yAxis.Min
+		}
+		if yAxis.Max != 0 {
+			defaults.Max = &yAxis.Max
+		}
+	}
+	defaults.Custom = custom
+	defaults.Thresholds = convertThresholds(spec.Thresholds)
+}
+
+func convertTable(panel *Panel, spec tablePanel.PluginSpec) {
+	panel.Type = "table"
+	panel.FieldConfig = &FieldConfig{Overrides: []any{}}
+	panel.Options = map[string]any{"showHeader": true, "cellHeight": "sm"}
+	for i := range panel.Targets {
+		panel.Targets[i].Format = "table"
+		panel.Targets[i].Instant = true
+		panel.Targets[i].Range = false
+	}
+
+	if len(spec.ColumnSettings) == 0 {
+		return
+	}
+	exclude := map[string]bool{}
+	index := map[string]int{}
+	rename := map[string]string{}
+	for i, column := range spec.ColumnSettings {
+		name := column.Name
+		if grafanaName, ok := columns[name]; ok {
+			name = grafanaName
+		}
+		index[name] = i
+		if column.Hide {
+			exclude[name] = true
+		}
+		if column.Header != "" {
+			rename[name] = column.Header
+		}
+		if column.Width > 0 {
+			panel.FieldConfig.Overrides = append(panel.FieldConfig.Overrides, map[string]any{
+				"matcher":    map[string]any{"id": "byName", "options": name},
+				"properties": []any{map[string]any{"id": "custom.width", "value": column.Width}},
+			})
+		}
+	}
+	panel.Transformations = append(panel.Transformations, Transformation{
+		ID: "organize",
+		Options: map[string]any{
+			"excludeByName": exclude,
+			"indexByName":   index,
+			"renameByName":  rename,
+		},
+	})
+}
+
+func convertGauge(panel *Panel, spec gaugePanel.PluginSpec) error {
+	calc, ok := calculations[spec.Calculation]
+	if !ok {
+		return fmt.Errorf("unsupported calculation %q", spec.Calculation)
+	}
+	panel.Type = "gauge"
+	panel.FieldConfig = &FieldConfig{Overrides: []any{}}
+	panel.Options = map[string]any{
+		"reduceOptions":        map[string]any{"calcs": []string{calc}, "fields": "", "values": false},
+		"showThresholdMarkers": true,
+		"showThresholdLabels":  false,
+	}
+	defaults := &panel.FieldConfig.Defaults
+	setFormat(defaults, spec.Format)
+	if spec.Max != 0 {
+		defaults.Max = &spec.Max
+	}
+	defaults.Thresholds = convertThresholds(spec.Thresholds)
+	return nil
+}
+
+func setFormat(defaults *FieldDefaults, format *commonSdk.Format) {
+	if format == nil {
+		return
+	}
+	defaults.Unit = units[format.Unit]
+	if format.DecimalPlaces > 0 {
+		defaults.Decimals = &format.DecimalPlaces
+	}
+}
+
+func convertThresholds(thresholds *commonSdk.Thresholds) *Thresholds {
+	result := &Thresholds{Mode: "absolute", Steps: []ThresholdStep{{Color: "green"}}}
+	if thresholds == nil {
+		return result
+	}
+	if thresholds.Mode == commonSdk.PercentMode {
+		result.Mode = "percentage"
+	}
+	if thresholds.DefaultColor != "" {
+		result.Steps[0].Color = thresholds.DefaultColor
+	}
+	for _, step := range thresholds.Steps {
+		color := step.Color
+		if color == "" {
+			color = "red"
+		}
+		result.Steps = append(result.Steps, ThresholdStep{Color: color, Value: &step.Value})
+	}
+	return result
+}
+
+func convertVariable(v dashboard.Variable) (Variable, error) {
+	switch spec := v.Spec.(type) {
+	case *dashboard.TextVariableSpec:
+		result := Variable{
+			Name:    spec.Name,
+			Type:    "textbox",
+			Query:   spec.Value,
+			Current: &VariableValue{Text: spec.Value, Value: spec.Value},
+		}
+		if spec.Constant {
+			result.Type = "constant"
+			result.Hide = 2
+		}
+		setDisplay(&result, spec.Display)
+		return result, nil
+	case *dashboard.ListVariableSpec:
+		return convertListVariable(spec)
+	default:
+		return Variable{}, fmt.Errorf("unsupported variable %q", v.Kind)
+	}
+}
+
+func convertListVariable(spec *dashboard.ListVariableSpec) (Variable, error) {
+	result := Variable{
+		Name:       spec.Name,
+		Multi:      spec.AllowMultiple,
+		IncludeAll: spec.AllowAllValue,
+		AllValue:   spec.CustomAllValue,
+		Regex:      spec.CapturingRegexp,
+	}
+	if spec.Sort != nil {
+		result.Sort = sorts[*spec.Sort]
+	}
+
+	plugin := spec.Plugin
+	switch plugin.Kind {
+	case labelValuesVar.PluginKind:
+		var pluginSpec labelValuesVar.PluginSpec
+		if err := perses.DecodePluginSpec(plugin, &pluginSpec); err != nil {
+			return Variable{}, err
+		}
+		// Grafana only takes one series selector, so only the first matcher is kept.
+		query := fmt.Sprintf("label_values(%s)", pluginSpec.LabelName)
+		if len(pluginSpec.Matchers) > 0 {
+			query = fmt.Sprintf("label_values(%s, %s)", pluginSpec.Matchers[0], pluginSpec.LabelName)
+		}
+		setQuery(&result, query, pluginSpec.Datasource)
+	case promqlVar.PluginKind:
+		var pluginSpec promqlVar.PluginSpec
+		if err := perses.DecodePluginSpec(plugin, &pluginSpec); err != nil {
+			return Variable{}, err
+		}
+		setQuery(&result, fmt.Sprintf("query_result(%s)", pluginSpec.Expr), pluginSpec.Datasource)
+		if pluginSpec.LabelName != "" && result.Regex == "" {
+			result.Regex = fmt.Sprintf(`/%s="([^"]*)"/`, pluginSpec.LabelName)
+		}
+	case staticListVariableKind:
+		var pluginSpec staticListVar.PluginSpec
+		if err := perses.DecodePluginSpec(plugin, &pluginSpec); err != nil {
+			return Variable{}, err
+		}
+		setStaticValues(&result, pluginSpec.Values)
+	default:
+		return Variable{}, fmt.Errorf("unsupported variable plugin %q", plugin.Kind)
+	}
+
+	if spec.DefaultValue != nil {
+		if result.Multi {
+			values := spec.DefaultValue.SliceValues
+			if len(values) == 0 {
+				values = []string{spec.DefaultValue.SingleValue}
+			}
+			result.Current = &VariableValue{Text: values, Value: values}
+		} else {
+			value := spec.DefaultValue.SingleValue
+			if value == "" && len(spec.DefaultValue.SliceValues) > 0 {
+				value = spec.DefaultValue.SliceValues[0]
+			}
+			result.Current = &VariableValue{Text: value, Value: value}
+		}
+		if result.Type == "interval" && strings.HasPrefix(result.Current.Value.(string), "$__") {
+			result.Current = &VariableValue{Text: "auto", Value: "$__auto_interval_" + result.Name}
+		}
+		for i := range result.Options {
+			result.Options[i].Selected = result.Options[i].Value == result.Current.Value
+		}
+	} else if result.IncludeAll {
+		result.Current = &VariableValue{Text: "All", Value: "$__all"}
+	}
+	setDisplay(&result, spec.Display)
+	return result, nil
+}
+
+// convertDatasourceVariable converts the static list of datasource names of a dashboard to a Grafana
+// datasource variable restricted to those names.
+func convertDatasourceVariable(v dashboard.Variable) (Variable, error) {
+	spec, ok := v.Spec.(*dashboard.ListVariableSpec)
+	if !ok || spec.Plugin.Kind != staticListVariableKind {
+		return Variable{}, fmt.Errorf("the datasource variable must be a static list")
+	}
+	var pluginSpec staticListVar.PluginSpec
+	if err := perses.DecodePluginSpec(spec.Plugin, &pluginSpec); err != nil {
+		return Variable{}, err
+	}
+
+	names := make([]string, 0, len(pluginSpec.Values))
+	for _, value := range pluginSpec.Values {
+		names = append(names, regexp.QuoteMeta(value))
+	}
+	result := Variable{
+		Name:  DatasourceVariable,
+		Label: "Data source",
+		Type:  "datasource",
+		Query: "prometheus",
+		Regex: fmt.Sprintf("/^(%s)$/", strings.Join(names, "|")),
+	}
+	if spec.DefaultValue != nil && spec.DefaultValue.SingleValue != "" {
+		result.Current = &VariableValue{Text: spec.DefaultValue.SingleValue, Value: spec.DefaultValue.SingleValue}
+	}
+	setDisplay(&result, spec.Display)
+	return result, nil
+}
+
+func setQuery(v *Variable, query string, selector *datasource.Selector) {
+	v.Type = "query"
+	v.Datasource = datasourceRef(selector)
+	v.Query = VariableQuery{Query: query, RefID: "PrometheusVariableQueryEditor-VariableQuery"}
+	v.Definition = query
+	// Refresh the values when the time range changes, as Perses does.
+	v.Refresh = 2
+}
+
+// setStaticValues converts a static list to a custom variable, or to an interval variable when every value
+// is a duration. The Perses $__interval and $__rate_interval values are not resolved by Grafana when used
+// as variable values, so they become the auto option of the interval variable.
+func setStaticValues(v *Variable, values []string) {
+	durations := []string{}
+	auto := false
+	for _, value := range values {
+		if value == "$__interval" || value == "$__rate_interval" {
+			auto = true
+		} else if _, err := model.ParseDuration(value); err == nil {
+			durations = append(durations, value)
+		} else {
+			durations = nil
+			break
+		}
+	}
+
+	if durations != nil {
+		v.Type = "interval"
+		v.Query = strings.Join(durations, ",")
+		v.Refresh = 2
+		if auto {
+			v.Auto = true
+			v.AutoCount = 30
+			v.AutoMin = "10s"
+			v.Options = append(v.Options, VariableOption{Text: "auto", Value: "$__auto_interval_" + v.Name})
+		}
+		values = durations
+	} else {
+		v.Type = "custom"
+		v.Query = strings.Join(values, ",")
+	}
+	for _, value := range values {
+		v.Options = append(v.Options, VariableOption{Text: value, Value: value})
+	}
+}
+
+func setDisplay(v *Variable, display *variable.Display) {
+	if display == nil {
+		return
+	}
+	v.Label = display.Name
+	v.Description = display.Description
+	if display.Hidden {
+		v.Hide = 2
+	}
+}
+
+// datasourceRef references the named Prometheus datasource, or the one of the datasource variable when
+// no datasource is named or the name is the datasource variable. Grafana references datasources by UID, so
+// the Perses name is used as the UID.
+func datasourceRef(selector *datasource.Selector) *DatasourceRef {
+	if selector != nil && selector.Name != "" && !strings.HasPrefix(selector.Name, "$") {
+		return &DatasourceRef{Type: "prometheus", UID: selector.Name}
+	}
+	return &DatasourceRef{Type: "prometheus", UID: "${" + DatasourceVariable + "}"}
+}
+
+// refID returns the Grafana reference of the i-th query of a panel: A, B, ..., Z, AA, AB, ...
+func refID(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return refID(i/26-1) + refID(i%26)
+}
@@ -0,0 +1,424 @@
+package grafana_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/config"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/dashboardstest"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/grafana"
+	commonSdk "github.com/perses/perses/go-sdk/common"
+	"github.com/perses/perses/go-sdk/dashboard"
+	"github.com/perses/perses/go-sdk/panel"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	gaugePanel "github.com/perses/perses/go-sdk/panel/gauge"
+	tablePanel "github.com/perses/perses/go-sdk/panel/table"
+	timeSeriesPanel "github.com/perses/perses/go-sdk/panel/time-series"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	promqlVar "github.com/perses/perses/go-sdk/prometheus/variable/promql"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+	staticListVar "github.com/perses/perses/go-sdk/variable/plugin/static-list"
+	textVar "github.com/perses/perses/go-sdk/variable/text-variable"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+	v1Dashboard "github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+func convert(t *testing.T, options ...dashboard.Option) grafana.Dashboard {
+	t.Helper()
+	builder, err := dashboard.New("test", append([]dashboard.Option{dashboard.ProjectName("default")}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := grafana.Convert(builder.Dashboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// convertPanel converts a dashboard holding only the panel and returns the converted panel.
+func convertPanel(t *testing.T, options ...panel.Option) grafana.Panel {
+	t.Helper()
+	result := convert(t, dashboard.AddPanelGroup("Group", panelgroup.AddPanel("Panel", options...)))
+	if len(result.Panels) != 2 {
+		t.Fatalf("expected a row and a panel, got %+v", result.Panels)
+	}
+	return result.Panels[1]
+}
+
+func TestConvertPanels(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []panel.Option
+		check   func(t *testing.T, p grafana.Panel)
+	}{
+		{
+			name: "time series",
+			options: []panel.Option{
+				timeSeriesPanel.Chart(
+					timeSeriesPanel.WithLegend(timeSeriesPanel.Legend{
+						Position: timeSeriesPanel.RightPosition,
+						Mode:     timeSeriesPanel.TableMode,
+						Values:   []commonSdk.Calculation{commonSdk.MeanCalculation, commonSdk.MaxCalculation},
+					}),
+					timeSeriesPanel.WithVisual(timeSeriesPanel.Visual{
+						Display:     timeSeriesPanel.BarDisplay,
+						AreaOpacity: 0.5,
+						Stack:       timeSeriesPanel.AllStack,
+					}),
+					timeSeriesPanel.WithYAxis(timeSeriesPanel.YAxis{
+						Format: &commonSdk.Format{Unit: string(commonSdk.BytesPerSecondsUnit)},
+						Label:  "Throughput",
+						Max:    100,
+					}),
+					timeSeriesPanel.Thresholds(commonSdk.Thresholds{Steps: []commonSdk.StepOption{{Value: 80}}}),
+				),
+			},
+			check: func(t *testing.T, p grafana.Panel) {
+				if p.Type != "timeseries" {
+					t.Errorf("expected a timeseries panel, got %q", p.Type)
+				}
+				legend := p.Options["legend"].(map[string]any)
+				if legend["displayMode"] != "table" || legend["placement"] != "right" || !slices.Equal(legend["calcs"].([]string), []string{"mean", "max"}) {
+					t.Errorf("unexpected legend %v", legend)
+				}
+				defaults := p.FieldConfig.Defaults
+				if defaults.Unit != "Bps" || defaults.Max == nil || *defaults.Max != 100 || defaults.Min != nil {
+					t.Errorf("unexpected unit and range %+v", defaults)
+				}
+				custom := defaults.Custom
+				if custom["drawStyle"] != "bars" || custom["fillOpacity"] != 50.0 || custom["axisLabel"] != "Throughput" {
+					t.Errorf("unexpected custom field config %v", custom)
+				}
+				if stacking := custom["stacking"].(map[string]any); stacking["mode"] != "normal" {
+					t.Errorf("expected normal stacking, got %v", stacking)
+				}
+				if steps := defaults.Thresholds.Steps; len(steps) != 2 || steps[0].Value != nil || steps[1].Color != "red" || *steps[1].Value != 80 {
+					t.Errorf("unexpected thresholds %+v", defaults.Thresholds)
+				}
+			},
+		},
+		{
+			name:    "time series without legend",
+			options: []panel.Option{timeSeriesPanel.Chart()},
+			check: func(t *testing.T, p grafana.Panel) {
+				if legend := p.Options["legend"].(map[string]any); legend["showLegend"] != false {
+					t.Errorf("expected the legend to be hidden, got %v", legend)
+				}
+			},
+		},
+		{
+			name: "table",
+			options: []panel.Option{
+				tablePanel.Table(tablePanel.WithColumnSettings([]tablePanel.ColumnSettings{
+					{Name: "instance", Header: "Instance", Width: 200},
+					{Name: "timestamp", Hide: true},
+					{Name: "value", Header: "Uptime"},
+				})),
+				panel.AddQuery(query.PromQL("up")),
+			},
+			check: func(t *testing.T, p grafana.Panel) {
+				if p.Type != "table" {
+					t.Errorf("expected a table panel, got %q", p.Type)
+				}
+				if target := p.Targets[0]; target.Format != "table" || !target.Instant || target.Range {
+					t.Errorf("expected an instant table query, got %+v", target)
+				}
+				if len(p.Transformations) != 1 || p.Transformations[0].ID != "organize" {
+					t.Fatalf("expected an organize transformation, got %+v", p.Transformations)
+				}
+				options := p.Transformations[0].Options.(map[string]any)
+				exclude, rename := options["excludeByName"].(map[string]bool), options["renameByName"].(map[string]string)
+				if !exclude["Time"] || rename["instance"] != "Instance" || rename["Value"] != "Uptime" {
+					t.Errorf("unexpected organize options %v", options)
+				}
+				if len(p.FieldConfig.Overrides) != 1 {
+					t.Errorf("expected a width override, got %v", p.FieldConfig.Overrides)
+				}
+			},
+		},
+		{
+			name: "gauge",
+			options: []panel.Option{
+				gaugePanel.Chart(
+					gaugePanel.Calculation(commonSdk.LastNumberCalculation),
+					gaugePanel.Format(commonSdk.Format{Unit: string(commonSdk.PercentUnit), DecimalPlaces: 1}),
+					gaugePanel.Max(100),
+					gaugePanel.Thresholds(commonSdk.Thresholds{
+						Mode:         commonSdk.PercentMode,
+						DefaultColor: "blue",
+						Steps:        []commonSdk.StepOption{{Value: 90, Color: "orange"}},
+					}),
+				),
+			},
+			check: func(t *testing.T, p grafana.Panel) {
+				if p.Type != "gauge" {
+					t.Errorf("expected a gauge panel, got %q", p.Type)
+				}
+				if calcs := p.Options["reduceOptions"].(map[string]any)["calcs"]; !slices.Equal(calcs.([]string), []string{"lastNotNull"}) {
+					t.Errorf("unexpected reducer %v", calcs)
+				}
+				defaults := p.FieldConfig.Defaults
+				if defaults.Unit != "percent" || *defaults.Decimals != 1 || *defaults.Max != 100 {
+					t.Errorf("unexpected field config %+v", defaults)
+				}
+				if th := defaults.Thresholds; th.Mode != "percentage" || th.Steps[0].Color != "blue" || th.Steps[1].Color != "orange" {
+					t.Errorf("unexpected thresholds %+v", th)
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.check(t, convertPanel(t, tc.options...))
+		})
+	}
+}
+
+func TestConvertPanelErrors(t *testing.T) {
+	for name, options := range map[string][]panel.Option{
+		"unsupported plugin":      {panel.Plugin(common.Plugin{Kind: "Markdown", Spec: map[string]any{"text": "hello"}})},
+		"unsupported calculation": {gaugePanel.Chart(gaugePanel.Calculation("median"))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			builder, err := dashboard.New("test", dashboard.ProjectName("default"), dashboard.AddPanelGroup("Group", panelgroup.AddPanel("Panel", options...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := grafana.Convert(builder.Dashboard); err == nil || !strings.Contains(err.Error(), `panel "Panel"`) {
+				t.Errorf("expected an error naming the panel, got %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertTargets(t *testing.T) {
+	p := convertPanel(t,
+		timeSeriesPanel.Chart(),
+		panel.AddQuery(query.PromQL(`sum by (instance) (rate(node_cpu_seconds_total[$interval]))`, query.SeriesNameFormat("{{instance}}"))),
+		panel.AddQuery(query.PromQL("up", query.Datasource("thanos"), query.MinStep(30*time.Second))),
+	)
+
+	want := []grafana.Target{
+		{
+			RefID:        "A",
+			Datasource:   &grafana.DatasourceRef{Type: "prometheus", UID: "${datasource}"},
+			Expr:         `sum by (instance) (rate(node_cpu_seconds_total[$interval]))`,
+			LegendFormat: "{{instance}}",
+			Range:        true,
+		},
+		{
+			RefID:      "B",
+			Datasource: &grafana.DatasourceRef{Type: "prometheus", UID: "thanos"},
+			Expr:       "up",
+			Interval:   "30s",
+			Range:      true,
+		},
+	}
+	if len(p.Targets) != len(want) {
+		t.Fatalf("expected %d targets, got %+v", len(want), p.Targets)
+	}
+	for i := range want {
+		got, _ := json.Marshal(p.Targets[i])
+		expected, _ := json.Marshal(want[i])
+		if string(got) != string(expected) {
+			t.Errorf("expected target %s, got %s", expected, got)
+		}
+	}
+	if *p.Datasource != *want[0].Datasource {
+		t.Errorf("expected the panel to use the datasource of its first query, got %+v", p.Datasource)
+	}
+}
+
+func TestConvertRows(t *testing.T) {
+	result := convert(t,
+		dashboard.AddPanelGroup("Open",
+			panelgroup.PanelsPerLine(2),
+			panelgroup.AddPanel("A", timeSeriesPanel.Chart()),
+			panelgroup.AddPanel("B", timeSeriesPanel.Chart()),
+			panelgroup.AddPanel("C", timeSeriesPanel.Chart()),
+		),
+		dashboard.AddPanelGroup("Closed", panelgroup.AddPanel("D", timeSeriesPanel.Chart())),
+		// Perses only shows a panel group collapsed when it is explicitly closed, which the SDK never does.
+		func(builder *dashboard.Builder) error {
+			layouts := builder.Dashboard.Spec.Layouts
+			layouts[len(layouts)-1].Spec.(v1Dashboard.GridLayoutSpec).Display.Collapse = &v1Dashboard.GridLayoutCollapse{Open: false}
+			return nil
+		},
+	)
+
+	var titles []string
+	for _, p := range result.Panels {
+		titles = append(titles, p.Type+":"+p.Title)
+	}
+	if want := []string{"row:Open", "timeseries:A", "timeseries:B", "timeseries:C", "row:Closed"}; !slices.Equal(titles, want) {
+		t.Fatalf("expected the panels %v, got %v", want, titles)
+	}
+
+	open, a, c, closed := result.Panels[0], result.Panels[1], result.Panels[3], result.Panels[4]
+	if *open.Collapsed || open.GridPos != (grafana.GridPos{H: 1, W: 24, X: 0, Y: 0}) {
+		t.Errorf("unexpected open row %+v", open)
+	}
+	if a.GridPos.Y != 1 || a.GridPos.W != 12 || c.GridPos.Y <= a.GridPos.Y {
+		t.Errorf("expected the panels below the row, two per line, got %+v and %+v", a.GridPos, c.GridPos)
+	}
+	if !*closed.Collapsed || len(closed.Panels) != 1 || closed.Panels[0].Title != "D" {
+		t.Errorf("expected the collapsed row to hold its panel, got %+v", closed)
+	}
+	if closed.GridPos.Y != c.GridPos.Y+c.GridPos.H {
+		t.Errorf("expected the collapsed row below the panels of the open one, got %+v", closed.GridPos)
+	}
+
+	ids := map[int]bool{}
+	for _, p := range append(result.Panels, closed.Panels...) {
+		if ids[p.ID] {
+			t.Errorf("panel id %d used twice", p.ID)
+		}
+		ids[p.ID] = true
+	}
+}
+
+func TestConvertVariables(t *testing.T) {
+	result := convert(t,
+		dashboard.AddVariable("job", listVar.List(
+			labelValuesVar.PrometheusLabelValues("job", labelValuesVar.Matchers(`up{env="prod"}`)),
+			listVar.AllowMultiple(true),
+			listVar.AllowAllValue(true),
+			listVar.DisplayName("Job"),
+		)),
+		dashboard.AddVariable("instance", listVar.List(
+			promqlVar.PrometheusPromQL(`up{job=~"$job"}`, promqlVar.LabelName("instance"), promqlVar.Datasource("thanos")),
+			listVar.DefaultValue("localhost:9100"),
+		)),
+		dashboard.AddVariable("env", listVar.List(
+			staticListVar.StaticList(staticListVar.Values("prod", "staging")),
+			listVar.DefaultValue("staging"),
+		)),
+		dashboard.AddVariable("interval", listVar.List(
+			staticListVar.StaticList(staticListVar.Values("$__rate_interval", "1m", "5m")),
+			listVar.DefaultValue("$__rate_interval"),
+		)),
+		dashboard.AddVariable("team", textVar.Text("sre", textVar.Constant(true))),
+		dashboard.AddVariable("filter", textVar.Text("", textVar.Hidden(true))),
+	)
+
+	variables := map[string]grafana.Variable{}
+	var names []string
+	for _, v := range result.Templating.List {
+		variables[v.Name] = v
+		names = append(names, v.Name)
+	}
+	if want := []string{"datasource", "job", "instance", "env", "interval", "team", "filter"}; !slices.Equal(names, want) {
+		t.Fatalf("expected the variables %v, got %v", want, names)
+	}
+
+	if v := variables["datasource"]; v.Type != "datasource" || v.Query != "prometheus" || v.Regex != "" {
+		t.Errorf("unexpected default datasource variable %+v", v)
+	}
+
+	job := variables["job"]
+	if job.Type != "query" || job.Query.(grafana.VariableQuery).Query != `label_values(up{env="prod"}, job)` || job.Label != "Job" {
+		t.Errorf("unexpected label values variable %+v", job)
+	}
+	if !job.Multi || !job.IncludeAll || job.Current.Value != "$__all" || job.Datasource.UID != "${datasource}" {
+		t.Errorf("expected a multi-value variable defaulting to all, got %+v", job)
+	}
+
+	instance := variables["instance"]
+	if instance.Definition != `query_result(up{job=~"$job"})` || instance.Regex != `/instance="([^"]*)"/` || instance.Datasource.UID != "thanos" {
+		t.Errorf("unexpected PromQL variable %+v", instance)
+	}
+	if instance.Current.Value != "localhost:9100" {
+		t.Errorf("unexpected default value %+v", instance.Current)
+	}
+
+	env := variables["env"]
+	if env.Type != "custom" || env.Query != "prod,staging" || len(env.Options) != 2 || env.Options[0].Selected || !env.Options[1].Selected {
+		t.Errorf("unexpected static list variable %+v", env)
+	}
+
+	interval := variables["interval"]
+	if interval.Type != "interval" || interval.Query != "1m,5m" || !interval.Auto {
+		t.Errorf("expected an interval variable with an auto option, got %+v", interval)
+	}
+	if interval.Current.Value != "$__auto_interval_interval" || !interval.Options[0].Selected || interval.Options[0].Text != "auto" {
+		t.Errorf("expected the auto option to replace $__rate_interval, got %+v", interval)
+	}
+
+	if team := variables["team"]; team.Type != "constant" || team.Query != "sre" || team.Hide != 2 {
+		t.Errorf("unexpected constant variable %+v", team)
+	}
+	if filter := variables["filter"]; filter.Type != "textbox" || filter.Hide != 2 {
+		t.Errorf("unexpected text variable %+v", filter)
+	}
+}
+
+func TestConvertDatasourceVariable(t *testing.T) {
+	result := convert(t, dashboard.AddVariable(grafana.DatasourceVariable, listVar.List(
+		staticListVar.StaticList(staticListVar.Values("prometheus", "thanos.eu")),
+		listVar.DefaultValue("thanos.eu"),
+	)))
+	if len(result.Templating.List) != 1 {
+		t.Fatalf("expected the datasource variable to replace the default one, got %+v", result.Templating.List)
+	}
+	v := result.Templating.List[0]
+	if v.Type != "datasource" || v.Regex != `/^(prometheus|thanos\.eu)$/` || v.Current.Value != "thanos.eu" {
+		t.Errorf("unexpected datasource variable %+v", v)
+	}
+
+	builder, err := dashboard.New("test", dashboard.ProjectName("default"),
+		dashboard.AddVariable(grafana.DatasourceVariable, textVar.Text("prometheus")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := grafana.Convert(builder.Dashboard); err == nil {
+		t.Error("expected an error for a datasource variable that is not a static list")
+	}
+}
+
+func TestConvertUnsupportedVariable(t *testing.T) {
+	builder, err := dashboard.New("test", dashboard.ProjectName("default"),
+		dashboard.AddVariable("custom", listVar.List(func(builder *listVar.Builder) error {
+			builder.ListVariableSpec.Plugin = common.Plugin{Kind: "CustomVariable", Spec: map[string]any{}}
+			return nil
+		})),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := grafana.Convert(builder.Dashboard); err == nil || !strings.Contains(err.Error(), `variable "custom"`) {
+		t.Errorf("expected an error naming the variable, got %v", err)
+	}
+}
+
+// TestGolden converts the dashboards of every mixin and compares them with the golden files
+// testdata/<dashboard>.json. With -update, the golden files are written instead:
+//
+//	go test ./internal/grafana/ -update
+func TestGolden(t *testing.T) {
+	for _, mixin := range config.MixinTypes {
+		for _, registration := range dashboards.Registrations(mixin, dashboards.Filter{}) {
+			t.Run(registration.Name, func(t *testing.T) {
+				builder, err := registration.Build()
+				if err != nil {
+					t.Fatal(err)
+				}
+				result, err := grafana.Convert(builder.Dashboard)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := json.MarshalIndent(result, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				dashboardstest.AssertGoldenFile(t, filepath.Join("testdata", registration.Name+".json"), append(got, '\n'))
+			})
+		}
+	}
+}
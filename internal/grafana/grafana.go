@@ -0,0 +1,142 @@
+package grafana
+
+// SchemaVersion is the Grafana dashboard schema version of the converted dashboards.
+const SchemaVersion = 39
+
+// DatasourceVariable is the name of the Grafana variable selecting the Prometheus datasource of the
+// panels and variables that do not name their datasource.
+const DatasourceVariable = "datasource"
+
+// Dashboard is the subset of the Grafana dashboard JSON model written by Convert.
+type Dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Tags          []string   `json:"tags"`
+	Editable      bool       `json:"editable"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeRange  `json:"time"`
+	Refresh       string     `json:"refresh,omitempty"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+type DatasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type Variable struct {
+	Name        string         `json:"name"`
+	Label       string         `json:"label,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Type        string         `json:"type"`
+	Datasource  *DatasourceRef `json:"datasource,omitempty"`
+	// Query is a string for custom, textbox, constant and datasource variables and a VariableQuery for
+	// query variables.
+	Query      any    `json:"query"`
+	Definition string `json:"definition,omitempty"`
+	Refresh    int    `json:"refresh,omitempty"`
+	// Auto, AutoCount and AutoMin are only set on interval variables offering an auto option.
+	Auto       bool             `json:"auto,omitempty"`
+	AutoCount  int              `json:"auto_count,omitempty"`
+	AutoMin    string           `json:"auto_min,omitempty"`
+	Regex      string           `json:"regex,omitempty"`
+	Sort       int              `json:"sort,omitempty"`
+	Multi      bool             `json:"multi"`
+	IncludeAll bool             `json:"includeAll"`
+	AllValue   string           `json:"allValue,omitempty"`
+	Hide       int              `json:"hide"`
+	Current    *VariableValue   `json:"current,omitempty"`
+	Options    []VariableOption `json:"options,omitempty"`
+}
+
+type VariableQuery struct {
+	Query string `json:"query"`
+	RefID string `json:"refId"`
+}
+
+// VariableValue is the selected value of a variable, a string or a list of strings for multi-value variables.
+type VariableValue struct {
+	Text  any `json:"text"`
+	Value any `json:"value"`
+}
+
+type VariableOption struct {
+	Text     string `json:"text"`
+	Value    string `json:"value"`
+	Selected bool   `json:"selected"`
+}
+
+type Panel struct {
+	ID              int              `json:"id"`
+	Type            string           `json:"type"`
+	Title           string           `json:"title"`
+	Description     string           `json:"description,omitempty"`
+	GridPos         GridPos          `json:"gridPos"`
+	Datasource      *DatasourceRef   `json:"datasource,omitempty"`
+	Targets         []Target         `json:"targets,omitempty"`
+	FieldConfig     *FieldConfig     `json:"fieldConfig,omitempty"`
+	Options         map[string]any   `json:"options,omitempty"`
+	Transformations []Transformation `json:"transformations,omitempty"`
+	// Collapsed and Panels are only set on rows, a collapsed row holding its panels.
+	Collapsed *bool   `json:"collapsed,omitempty"`
+	Panels    []Panel `json:"panels,omitempty"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Target struct {
+	RefID        string         `json:"refId"`
+	Datasource   *DatasourceRef `json:"datasource,omitempty"`
+	Expr         string         `json:"expr"`
+	LegendFormat string         `json:"legendFormat,omitempty"`
+	Interval     string         `json:"interval,omitempty"`
+	Format       string         `json:"format,omitempty"`
+	Instant      bool           `json:"instant,omitempty"`
+	Range        bool           `json:"range"`
+}
+
+type FieldConfig struct {
+	Defaults  FieldDefaults `json:"defaults"`
+	Overrides []any         `json:"overrides"`
+}
+
+type FieldDefaults struct {
+	Unit       string         `json:"unit,omitempty"`
+	Decimals   *int           `json:"decimals,omitempty"`
+	Min        *float64       `json:"min,omitempty"`
+	Max        *float64       `json:"max,omitempty"`
+	Thresholds *Thresholds    `json:"thresholds,omitempty"`
+	Custom     map[string]any `json:"custom,omitempty"`
+}
+
+type Thresholds struct {
+	Mode  string          `json:"mode"`
+	Steps []ThresholdStep `json:"steps"`
+}
+
+// ThresholdStep is a threshold of a panel, the base step having no value.
+type ThresholdStep struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+}
+
+type Transformation struct {
+	ID      string `json:"id"`
+	Options any    `json:"options"`
+}
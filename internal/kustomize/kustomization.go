@@ -0,0 +1,75 @@
+package kustomize
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kustomization is the kustomize file listing the generated custom resources.
+type Kustomization struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Resources  []string `yaml:"resources"`
+}
+
+type KustomizationWriter struct {
+	enabled   bool
+	resources map[string][]string
+}
+
+// NewKustomizationWriter returns a writer of the kustomizations, which writes nothing unless enabled.
+func NewKustomizationWriter(enabled bool) *KustomizationWriter {
+	return &KustomizationWriter{
+		enabled:   enabled,
+		resources: map[string][]string{},
+	}
+}
+
+// Add lists the resources in the kustomization of the directory. Resources outside of the directory are
+// listed in the kustomization of their own directory instead, since kustomize only loads the files below
+// the kustomization root.
+func (w *KustomizationWriter) Add(dir string, paths ...string) {
+	for _, path := range paths {
+		root := dir
+		resource, err := filepath.Rel(dir, path)
+		if err != nil || resource == ".." || strings.HasPrefix(resource, ".."+string(filepath.Separator)) {
+			root, resource = filepath.Dir(path), filepath.Base(path)
+		}
+		root = filepath.Clean(root)
+		w.resources[root] = append(w.resources[root], filepath.ToSlash(resource))
+	}
+}
+
+// Write writes a kustomization.yaml in every directory holding resources. It does nothing unless the
+// writer is enabled.
+func (w *KustomizationWriter) Write() error {
+	if !w.enabled {
+		return nil
+	}
+	if len(w.resources) == 0 {
+		return errors.New("--kustomization requires --output=operator or --rules-output=operator")
+	}
+
+	var errs []error
+	for dir, resources := range w.resources {
+		sort.Strings(resources)
+		output, err := yaml.Marshal(Kustomization{
+			APIVersion: "kustomize.config.k8s.io/v1beta1",
+			Kind:       "Kustomization",
+			Resources:  resources,
+		})
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, "kustomization.yaml"), output, os.ModePerm)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("kustomization %q: %w", dir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
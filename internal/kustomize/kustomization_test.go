@@ -0,0 +1,124 @@
+package kustomize_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/config"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/kustomize"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	nodeexporterrules "github.com/nicolastakashi/community-perses-dashboards/internal/rules/node_exporter"
+	"gopkg.in/yaml.v3"
+)
+
+func readYAML(t *testing.T, path string, value any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, value); err != nil {
+		t.Fatalf("invalid YAML in %s: %v", path, err)
+	}
+}
+
+func TestKustomizationWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	dashboardWriter := dashboards.NewDashboardWriter(dashboards.Output{
+		Format:   dashboards.OperatorOutput,
+		Dir:      dir,
+		Metadata: dashboards.PersesDashboardMetadata{Namespace: "monitoring"},
+	})
+	var names []string
+	for _, registration := range dashboards.Registrations(config.NodeExporterMixin, dashboards.Filter{}) {
+		dashboardWriter.Add(registration.Build())
+		names = append(names, registration.Name)
+	}
+	ruleWriter := rules.NewRuleWriter(rules.OperatorOutput, filepath.Join(dir, "rules"))
+	ruleWriter.Add(nodeexporterrules.BuildNodeExporterRules(rules.Options{}))
+	if err := dashboardWriter.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ruleWriter.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	kustomizationWriter := kustomize.NewKustomizationWriter(true)
+	kustomizationWriter.Add(dir, append(dashboardWriter.Resources(), ruleWriter.Resources()...)...)
+	if err := kustomizationWriter.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	var kustomization kustomize.Kustomization
+	readYAML(t, filepath.Join(dir, "kustomization.yaml"), &kustomization)
+	if kustomization.APIVersion != "kustomize.config.k8s.io/v1beta1" || kustomization.Kind != "Kustomization" {
+		t.Errorf("unexpected kustomization %s %s", kustomization.APIVersion, kustomization.Kind)
+	}
+	want := []string{"rules/node-exporter-rules.yaml"}
+	for _, name := range names {
+		want = append(want, name+".yaml")
+	}
+	slices.Sort(want)
+	if !slices.Equal(kustomization.Resources, want) {
+		t.Fatalf("expected the resources %v, got %v", want, kustomization.Resources)
+	}
+
+	for _, name := range names {
+		var resource dashboards.PersesDashboard
+		readYAML(t, filepath.Join(dir, name+".yaml"), &resource)
+		if resource.APIVersion != "perses.dev/v1alpha1" || resource.Kind != "PersesDashboard" {
+			t.Errorf("%s: unexpected resource %s %s", name, resource.APIVersion, resource.Kind)
+		}
+		if resource.Metadata.Name != name || resource.Metadata.Namespace != "monitoring" {
+			t.Errorf("%s: unexpected metadata %+v", name, resource.Metadata)
+		}
+		if len(resource.Spec.Panels) == 0 {
+			t.Errorf("%s: expected the dashboard panels in the spec", name)
+		}
+	}
+
+	var rule rules.PrometheusRule
+	readYAML(t, filepath.Join(dir, "rules", "node-exporter-rules.yaml"), &rule)
+	if rule.APIVersion != "monitoring.coreos.com/v1" || rule.Kind != "PrometheusRule" || rule.Metadata.Name != "node-exporter-rules" {
+		t.Errorf("unexpected rule resource %+v", rule.Metadata)
+	}
+}
+
+func TestKustomizationWriterOutsideResources(t *testing.T) {
+	dir, rulesDir := t.TempDir(), t.TempDir()
+
+	kustomizationWriter := kustomize.NewKustomizationWriter(true)
+	kustomizationWriter.Add(dir, filepath.Join(dir, "b.yaml"), filepath.Join(rulesDir, "rules.yaml"), filepath.Join(dir, "a.yaml"))
+	if err := kustomizationWriter.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	// kustomize only loads the files below the kustomization root, so the rules get their own kustomization.
+	for path, want := range map[string][]string{dir: {"a.yaml", "b.yaml"}, rulesDir: {"rules.yaml"}} {
+		var kustomization kustomize.Kustomization
+		readYAML(t, filepath.Join(path, "kustomization.yaml"), &kustomization)
+		if !slices.Equal(kustomization.Resources, want) {
+			t.Errorf("%s: expected the resources %v, got %v", path, want, kustomization.Resources)
+		}
+	}
+}
+
+func TestKustomizationWriterDisabled(t *testing.T) {
+	dir := t.TempDir()
+	kustomizationWriter := kustomize.NewKustomizationWriter(false)
+	kustomizationWriter.Add(dir, filepath.Join(dir, "a.yaml"))
+	if err := kustomizationWriter.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "kustomization.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no kustomization when disabled, got %v", err)
+	}
+
+	if err := kustomize.NewKustomizationWriter(true).Write(); err == nil {
+		t.Error("expected an error without any custom resource")
+	}
+}
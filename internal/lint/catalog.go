@@ -0,0 +1,203 @@
+package lint
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// catalogFS holds a catalog file per exporter version, catalog/<exporter>/<version>.txt, written by
+// gencatalog from the /metrics text of the exporter.
+//
+//go:embed catalog
+var catalogFS embed.FS
+
+// Family is a metric family as exposed on /metrics, such as prometheus_http_requests_total counter.
+type Family struct {
+	Name string
+	Type string
+}
+
+// Series returns the names of the series of the family: the _bucket, _sum and _count series of a
+// histogram, the summary series along with its _sum and _count series, and the family name otherwise.
+func (f Family) Series() []string {
+	switch f.Type {
+	case "histogram", "gaugehistogram":
+		return []string{f.Name + "_bucket", f.Name + "_sum", f.Name + "_count"}
+	case "summary":
+		return []string{f.Name, f.Name + "_sum", f.Name + "_count"}
+	default:
+		return []string{f.Name}
+	}
+}
+
+// Version is the set of series exposed by a version of an exporter.
+type Version struct {
+	Name   string
+	Series map[string]bool
+}
+
+// Catalog lists the series exposed by the versions of an exporter, from the oldest to the latest.
+type Catalog struct {
+	Exporter string
+	Versions []Version
+}
+
+// Exporters returns the exporters with an embedded catalog.
+func Exporters() []string {
+	entries, _ := fs.ReadDir(catalogFS, "catalog")
+	exporters := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			exporters = append(exporters, entry.Name())
+		}
+	}
+	return exporters
+}
+
+// LoadCatalog returns the embedded catalog of the exporter.
+func LoadCatalog(exporter string) (*Catalog, error) {
+	dir := path.Join("catalog", exporter)
+	entries, err := fs.ReadDir(catalogFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no metric catalog for exporter %q", exporter)
+	}
+
+	catalog := &Catalog{Exporter: exporter}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+		file, err := catalogFS.Open(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		families, err := ReadCatalog(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("catalog %s/%s: %w", exporter, entry.Name(), err)
+		}
+
+		version := Version{Name: strings.TrimSuffix(entry.Name(), ".txt"), Series: map[string]bool{}}
+		for _, family := range families {
+			for _, series := range family.Series() {
+				version.Series[series] = true
+			}
+		}
+		catalog.Versions = append(catalog.Versions, version)
+	}
+	if len(catalog.Versions) == 0 {
+		return nil, fmt.Errorf("no metric catalog for exporter %q", exporter)
+	}
+	sort.Slice(catalog.Versions, func(i, j int) bool {
+		return compareVersions(catalog.Versions[i].Name, catalog.Versions[j].Name) < 0
+	})
+	return catalog, nil
+}
+
+// Latest returns the latest version of the exporter.
+func (c *Catalog) Latest() Version {
+	return c.Versions[len(c.Versions)-1]
+}
+
+// LastVersion returns the latest version exposing the series, and false when no version does.
+func (c *Catalog) LastVersion(series string) (string, bool) {
+	for i := len(c.Versions) - 1; i >= 0; i-- {
+		if c.Versions[i].Series[series] {
+			return c.Versions[i].Name, true
+		}
+	}
+	return "", false
+}
+
+// ReadCatalog reads a catalog file, made of a "<name> <type>" line per metric family. Empty lines and
+// lines starting with # are skipped.
+func ReadCatalog(r io.Reader) ([]Family, error) {
+	var families []Family
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<name> <type>\", got %q", line, text)
+		}
+		families = append(families, Family{Name: fields[0], Type: fields[1]})
+	}
+	return families, scanner.Err()
+}
+
+// WriteCatalog writes the families of an exporter version in the format read by ReadCatalog, sorted by name.
+func WriteCatalog(w io.Writer, exporter string, version string, families []Family) error {
+	sort.Slice(families, func(i, j int) bool { return families[i].Name < families[j].Name })
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Metric families exposed by %s %s, one \"<name> <type>\" per line.\n", exporter, version)
+	fmt.Fprintf(bw, "# Regenerate from the /metrics text of the exporter with:\n")
+	fmt.Fprintf(bw, "#   go run ./internal/lint/gencatalog -exporter %s -version %s metrics.txt\n", exporter, version)
+	for _, family := range families {
+		fmt.Fprintf(bw, "%s %s\n", family.Name, family.Type)
+	}
+	return bw.Flush()
+}
+
+// ParseMetrics returns the metric families of the Prometheus text exposition format, as served on /metrics.
+func ParseMetrics(r io.Reader) ([]Family, error) {
+	var parser expfmt.TextParser
+	metricFamilies, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+	families := make([]Family, 0, len(metricFamilies))
+	for name, metricFamily := range metricFamilies {
+		families = append(families, Family{Name: name, Type: familyType(metricFamily.GetType())})
+	}
+	return families, nil
+}
+
+func familyType(metricType dto.MetricType) string {
+	switch metricType {
+	case dto.MetricType_COUNTER:
+		return "counter"
+	case dto.MetricType_GAUGE:
+		return "gauge"
+	case dto.MetricType_SUMMARY:
+		return "summary"
+	case dto.MetricType_HISTOGRAM:
+		return "histogram"
+	case dto.MetricType_GAUGE_HISTOGRAM:
+		return "gaugehistogram"
+	default:
+		return "untyped"
+	}
+}
+
+// compareVersions compares two versions such as v2.53.3 number by number.
+func compareVersions(a string, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := range max(len(aParts), len(bParts)) {
+		var aNumber, bNumber int
+		if i < len(aParts) {
+			aNumber, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNumber, _ = strconv.Atoi(bParts[i])
+		}
+		if aNumber != bNumber {
+			return aNumber - bNumber
+		}
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+// Command gencatalog writes the metric catalog of an exporter version, embedded by the lint package, from the
+// /metrics text of the exporter:
+//
+//	curl -s localhost:9090/metrics > metrics.txt
+//	go run ./internal/lint/gencatalog -exporter prometheus -version v3.2.1 metrics.txt
+//
+// Several dumps can be given, for instance of exporters with different collectors enabled, and their metric
+// families are merged.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/lint"
+)
+
+func main() {
+	exporter := flag.String("exporter", "", "exporter of the metrics, such as prometheus or node_exporter")
+	version := flag.String("version", "", "version of the exporter, such as v3.2.1")
+	outputDir := flag.String("output-dir", "internal/lint/catalog", "directory of the catalogs")
+	flag.Parse()
+
+	if *exporter == "" || *version == "" || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: gencatalog -exporter <exporter> -version <version> <metrics.txt>...")
+		os.Exit(2)
+	}
+	if err := generate(*exporter, *version, *outputDir, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(exporter string, version string, outputDir string, dumps []string) error {
+	seen := map[string]bool{}
+	var families []lint.Family
+	for _, dump := range dumps {
+		file, err := os.Open(dump)
+		if err != nil {
+			return err
+		}
+		dumpFamilies, err := lint.ParseMetrics(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", dump, err)
+		}
+		for _, family := range dumpFamilies {
+			if !seen[family.Name] {
+				seen[family.Name] = true
+				families = append(families, family)
+			}
+		}
+	}
+
+	dir := filepath.Join(outputDir, exporter)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(dir, version+".txt"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return lint.WriteCatalog(file, exporter, version, families)
+}
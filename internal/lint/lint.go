@@ -0,0 +1,328 @@
+// Package lint checks the PromQL queries of the dashboards offline, against the metric catalogs of the
+// exporters embedded in the package.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	labelNamesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-names"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	promqlVar "github.com/perses/perses/go-sdk/prometheus/variable/promql"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// builtinMetrics are the series Prometheus writes itself for every target and alert, whatever the exporter.
+var builtinMetrics = []string{
+	"up",
+	"scrape_duration_seconds",
+	"scrape_samples_scraped",
+	"scrape_samples_post_metric_relabeling",
+	"scrape_series_added",
+	"ALERTS",
+	"ALERTS_FOR_STATE",
+}
+
+// Issue is a problem found in a query of a dashboard.
+type Issue struct {
+	Severity  Severity
+	Dashboard string
+	// Object names the query, such as `panel "CPU / CPU Usage" query 1` or `variable "job"`.
+	Object  string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: dashboard %q: %s: %s", i.Severity, i.Dashboard, i.Object, i.Message)
+}
+
+// Linter checks queries against the catalog of an exporter. A metric exposed by no version of the exporter is
+// an error, and a metric the latest version no longer exposes is a warning, unless the query falls back with
+// `or` to metrics the latest version exposes, as the queries supporting both the old and new names do.
+type Linter struct {
+	catalog *Catalog
+	// extra holds the metrics known whatever the exporter version, the builtin and recorded ones.
+	extra map[string]bool
+	// names holds every known metric, the candidates of the suggestions.
+	names []string
+}
+
+// NewLinter returns a linter of the queries using the metrics of the catalog, along with the metrics recorded
+// by the rules of the mixin.
+func NewLinter(catalog *Catalog, recordedMetrics []string) *Linter {
+	l := &Linter{catalog: catalog, extra: map[string]bool{}}
+	for _, name := range append(append([]string{}, builtinMetrics...), recordedMetrics...) {
+		l.extra[name] = true
+	}
+
+	seen := map[string]bool{}
+	for name := range l.extra {
+		seen[name] = true
+		l.names = append(l.names, name)
+	}
+	for _, version := range catalog.Versions {
+		for name := range version.Series {
+			if !seen[name] {
+				seen[name] = true
+				l.names = append(l.names, name)
+			}
+		}
+	}
+	sort.Strings(l.names)
+	return l
+}
+
+// Lint checks the queries of the panels and the matchers and expressions of the Prometheus variables of the
+// dashboard, along with the match types of their matchers on the list variables of the dashboard, and the
+// references to the variables, see LintVariables. It fails on queries of another plugin.
+func (l *Linter) Lint(d v1.Dashboard) ([]Issue, error) {
+	d, err := perses.Normalize(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	add := func(object string, queryIssues []Issue) {
+		for _, issue := range queryIssues {
+			issue.Dashboard = d.Metadata.Name
+			issue.Object = object
+			issues = append(issues, issue)
+		}
+	}
+
+	multiValue := map[string]bool{}
+	for _, v := range d.Spec.Variables {
+		if list, ok := v.Spec.(*dashboard.ListVariableSpec); ok {
+			multiValue[list.Name] = list.AllowMultiple || list.AllowAllValue
+		}
+	}
+
+	for _, v := range d.Spec.Variables {
+		list, ok := v.Spec.(*dashboard.ListVariableSpec)
+		if !ok {
+			continue
+		}
+		queries, err := variableQueries(list)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", list.Name, err)
+		}
+		for _, query := range queries {
+			add(fmt.Sprintf("variable %q", list.Name), append(l.LintQuery(query), LintVariableMatchers(query, multiValue)...))
+		}
+	}
+
+	panels, err := perses.Panels(d)
+	if err != nil {
+		return nil, err
+	}
+	for _, panel := range panels {
+		queries, err := perses.PanelQueries(panel.Panel)
+		if err != nil {
+			return nil, fmt.Errorf("panel %q: %w", panel.Name(), err)
+		}
+		for i, query := range queries {
+			add(fmt.Sprintf("panel %q query %d", panel.Name(), i+1), append(l.LintQuery(query.Query), LintVariableMatchers(query.Query, multiValue)...))
+		}
+	}
+
+	variableIssues, err := LintVariables(d)
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, variableIssues...), nil
+}
+
+// variableQueries returns the series selectors or the expression of a Prometheus variable, and nothing for
+// the variables of other plugins.
+func variableQueries(list *dashboard.ListVariableSpec) ([]string, error) {
+	switch list.Plugin.Kind {
+	case labelValuesVar.PluginKind:
+		var spec labelValuesVar.PluginSpec
+		err := perses.DecodePluginSpec(list.Plugin, &spec)
+		return spec.Matchers, err
+	case labelNamesVar.PluginKind:
+		var spec labelNamesVar.PluginSpec
+		err := perses.DecodePluginSpec(list.Plugin, &spec)
+		return spec.Matchers, err
+	case promqlVar.PluginKind:
+		var spec promqlVar.PluginSpec
+		err := perses.DecodePluginSpec(list.Plugin, &spec)
+		return []string{spec.Expr}, err
+	}
+	return nil, nil
+}
+
+// LintQuery checks a query, which may reference Perses variables. The issues returned do not name the
+// dashboard and the query, Lint does.
+func (l *Linter) LintQuery(query string) []Issue {
+	expr, err := promql.ParseExpr(query)
+	if err != nil {
+		return []Issue{{Severity: Error, Message: fmt.Sprintf("invalid PromQL expression: %v", err)}}
+	}
+
+	var issues []Issue
+	reported := map[string]bool{}
+	parser.Inspect(expr.AST, func(node parser.Node, path []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		name := metricName(selector)
+		if _, isVariable := expr.Variable(name); name == "" || isVariable || reported[name] {
+			return nil
+		}
+
+		if !l.known(name) {
+			message := fmt.Sprintf("unknown metric %q", name)
+			if suggestion := l.suggest(name); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			issues = append(issues, Issue{Severity: Error, Message: message})
+			reported[name] = true
+		} else if !l.current(name) && !l.fallsBack(node, path) {
+			lastVersion, _ := l.catalog.LastVersion(name)
+			issues = append(issues, Issue{
+				Severity: Warning,
+				Message: fmt.Sprintf("metric %q is not exposed by %s %s, it was last exposed by %s",
+					name, l.catalog.Exporter, l.catalog.Latest().Name, lastVersion),
+			})
+			reported[name] = true
+		}
+		return nil
+	})
+	return issues
+}
+
+// LintVariableMatchers checks that the matchers on the variables use a regex match type when the variable
+// allows several values or all of them, since Perses joins the values into a regex, and an equality one
+// otherwise. multiValue tells, for each list variable named without $, whether it allows several values.
+// Invalid queries have no issue, LintQuery reports them.
+func LintVariableMatchers(query string, multiValue map[string]bool) []Issue {
+	expr, err := promql.ParseExpr(query)
+	if err != nil {
+		return nil
+	}
+
+	var issues []Issue
+	for _, m := range expr.VariableMatchers() {
+		multi, ok := multiValue[m.Variable]
+		if !ok {
+			continue
+		}
+		if want := promql.VariableMatchType(m.Matcher.Type, multi); want != m.Matcher.Type {
+			values := "a single value"
+			if multi {
+				values = "several values"
+			}
+			issues = append(issues, Issue{
+				Severity: Error,
+				Message: fmt.Sprintf("label %q is matched with %s on variable %q, which allows %s, use %s",
+					m.Matcher.Name, m.Matcher.Type, m.Variable, values, want),
+			})
+		}
+	}
+	return issues
+}
+
+// known tells whether any version of the exporter exposes the metric, or it is a builtin or recorded metric.
+func (l *Linter) known(name string) bool {
+	_, ok := l.catalog.LastVersion(name)
+	return ok || l.extra[name]
+}
+
+// current tells whether the latest version of the exporter exposes the metric, or it is a builtin or
+// recorded metric.
+func (l *Linter) current(name string) bool {
+	return l.catalog.Latest().Series[name] || l.extra[name]
+}
+
+// fallsBack tells whether the node is an operand of an `or` whose other operand only selects current metrics.
+func (l *Linter) fallsBack(node parser.Node, path []parser.Node) bool {
+	child := node
+	for i := len(path) - 1; i >= 0; i-- {
+		if binary, ok := path[i].(*parser.BinaryExpr); ok && binary.Op == parser.LOR {
+			other := binary.RHS
+			if parser.Node(binary.RHS) == child {
+				other = binary.LHS
+			}
+			if l.selectsCurrentMetrics(other) {
+				return true
+			}
+		}
+		child = path[i]
+	}
+	return false
+}
+
+func (l *Linter) selectsCurrentMetrics(expr parser.Expr) bool {
+	found, current := false, true
+	parser.Inspect(expr, func(node parser.Node, _ []parser.Node) error {
+		if selector, ok := node.(*parser.VectorSelector); ok {
+			if name := metricName(selector); name != "" {
+				found = true
+				current = current && l.current(name)
+			}
+		}
+		return nil
+	})
+	return found && current
+}
+
+// suggest returns the known metric closest to the name, and nothing when none is close enough to be a typo.
+func (l *Linter) suggest(name string) string {
+	best, bestDistance := "", max(2, len(name)/4)+1
+	for _, candidate := range l.names {
+		distance := levenshtein(name, candidate)
+		if distance < bestDistance || distance == bestDistance && best != "" && l.current(candidate) && !l.current(best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// metricName returns the metric name of a selector, given either before the braces or with an __name__
+// equality matcher, and nothing when the selector matches several metric names.
+func metricName(selector *parser.VectorSelector) string {
+	if selector.Name != "" {
+		return selector.Name
+	}
+	for _, matcher := range selector.LabelMatchers {
+		if matcher.Name == labels.MetricName && matcher.Type == labels.MatchEqual {
+			return matcher.Value
+		}
+	}
+	return ""
+}
+
+// levenshtein returns the number of single character insertions, deletions and substitutions turning a into b.
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,194 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/perses/perses/go-sdk/dashboard"
+	"github.com/perses/perses/go-sdk/panel"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	timeSeriesPanel "github.com/perses/perses/go-sdk/panel/time-series"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func TestLintQuery(t *testing.T) {
+	catalog, err := LoadCatalog("prometheus")
+	if err != nil {
+		t.Fatal(err)
+	}
+	linter := NewLinter(catalog, []string{"instance:prometheus_http_requests:rate5m"})
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "known metric",
+			query: `sum by (job) (rate(prometheus_http_requests_total{job=~"$job"}[$__rate_interval]))`,
+		},
+		{
+			name:  "histogram series",
+			query: `histogram_quantile(0.99, rate(prometheus_http_request_duration_seconds_bucket[5m]))`,
+		},
+		{
+			name:  "builtin and recorded metrics",
+			query: `up == 0 or instance:prometheus_http_requests:rate5m > 0 or {__name__="ALERTS"}`,
+		},
+		{
+			name:  "metric name variable",
+			query: `rate($metric[5m])`,
+		},
+		{
+			name:  "typo",
+			query: `rate(prometheus_http_request_total[5m])`,
+			want:  []string{`error: unknown metric "prometheus_http_request_total", did you mean "prometheus_http_requests_total"?`},
+		},
+		{
+			name:  "unknown metric",
+			query: `foo_bar_baz`,
+			want:  []string{`error: unknown metric "foo_bar_baz"`},
+		},
+		{
+			name:  "removed metric",
+			query: `rate(prometheus_remote_storage_succeeded_samples_total[5m])`,
+			want:  []string{`warning: metric "prometheus_remote_storage_succeeded_samples_total" is not exposed by prometheus v3.2.1, it was last exposed by v2.22.2`},
+		},
+		{
+			name:  "removed metric falling back to the new name",
+			query: `rate(prometheus_remote_storage_succeeded_samples_total[5m]) or rate(prometheus_remote_storage_samples_total[5m])`,
+		},
+		{
+			name:  "invalid expression",
+			query: `sum(rate(up[5m])`,
+			want:  []string{`error: invalid PromQL expression`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			issues := linter.LintQuery(tc.query)
+			if len(issues) != len(tc.want) {
+				t.Fatalf("expected %d issue(s), got %v", len(tc.want), issues)
+			}
+			for i, issue := range issues {
+				if got := string(issue.Severity) + ": " + issue.Message; !strings.HasPrefix(got, tc.want[i]) {
+					t.Errorf("expected %q, got %q", tc.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	for _, exporter := range Exporters() {
+		catalog, err := LoadCatalog(exporter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i < len(catalog.Versions); i++ {
+			if compareVersions(catalog.Versions[i-1].Name, catalog.Versions[i].Name) >= 0 {
+				t.Errorf("%s versions not sorted: %s before %s", exporter, catalog.Versions[i-1].Name, catalog.Versions[i].Name)
+			}
+		}
+	}
+
+	if _, err := LoadCatalog("unknown"); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+}
+
+func TestParseMetrics(t *testing.T) {
+	families, err := ParseMetrics(strings.NewReader(`# HELP http_request_duration_seconds Duration of the requests.
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{le="1"} 1
+http_request_duration_seconds_bucket{le="+Inf"} 2
+http_request_duration_seconds_sum 1.5
+http_request_duration_seconds_count 2
+# TYPE http_requests_total counter
+http_requests_total{code="200"} 2
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := WriteCatalog(&sb, "test", "v1.0.0", families); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadCatalog(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Family{
+		{Name: "http_request_duration_seconds", Type: "histogram"},
+		{Name: "http_requests_total", Type: "counter"},
+	}
+	if len(read) != len(want) || read[0] != want[0] || read[1] != want[1] {
+		t.Fatalf("expected %v, got %v", want, read)
+	}
+	if series := read[0].Series(); strings.Join(series, " ") != "http_request_duration_seconds_bucket http_request_duration_seconds_sum http_request_duration_seconds_count" {
+		t.Errorf("unexpected histogram series %v", series)
+	}
+}
+
+func TestLintVariableMatchers(t *testing.T) {
+	multiValue := map[string]bool{"instance": true, "job": false}
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{query: `up{instance=~"$instance",job="$job",mode="$mode"}`},
+		{query: `up{instance="${instance}"}`, want: []string{`label "instance" is matched with = on variable "instance", which allows several values, use =~`}},
+		{query: `up{job!~"$job"}`, want: []string{`label "job" is matched with !~ on variable "job", which allows a single value, use !=`}},
+		{query: `up{instance="${instance:csv}"}`},
+	} {
+		issues := LintVariableMatchers(tc.query, multiValue)
+		if len(issues) != len(tc.want) {
+			t.Fatalf("%s: expected %d issue(s), got %v", tc.query, len(tc.want), issues)
+		}
+		for i, issue := range issues {
+			if issue.Severity != Error || issue.Message != tc.want[i] {
+				t.Errorf("%s: expected %q, got %v", tc.query, tc.want[i], issue)
+			}
+		}
+	}
+}
+
+func TestLintVariables(t *testing.T) {
+	builder, err := dashboard.New("test",
+		dashboard.AddVariable("job",
+			listVar.List(labelValuesVar.PrometheusLabelValues("job", labelValuesVar.Matchers(`up{cluster="$cluster"}`))),
+		),
+		dashboard.AddVariable("cluster", listVar.List(labelValuesVar.PrometheusLabelValues("cluster"))),
+		dashboard.AddVariable("unused", listVar.List(labelValuesVar.PrometheusLabelValues("unused"))),
+		dashboard.AddPanelGroup("Group",
+			panelgroup.AddPanel("Up",
+				timeSeriesPanel.Chart(),
+				panel.AddQuery(query.PromQL(`up{job="$job",instance="${instance}"}[$__rate_interval]`, query.Datasource("$datasource"))),
+			),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := LintVariables(builder.Dashboard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`error: dashboard "test": variable "job": variable "cluster" is referenced before it is defined`,
+		`error: dashboard "test": panel "Group / Up" query 1: undefined variable "datasource"`,
+		`error: dashboard "test": panel "Group / Up" query 1: undefined variable "instance"`,
+		`warning: dashboard "test": variable "unused": variable is never used`,
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %v", len(want), issues)
+	}
+	for i, issue := range issues {
+		if issue.String() != want[i] {
+			t.Errorf("expected %q, got %q", want[i], issue)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/perses/perses/go-sdk/datasource"
+	labelNamesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-names"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	promqlVar "github.com/perses/perses/go-sdk/prometheus/variable/promql"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+// LintVariables checks the references to the variables of the dashboard, in the queries and datasources of
+// the panels and Prometheus variables. A reference to a variable the dashboard does not define is an error,
+// as is a variable referencing one defined after it, since Perses resolves the variables in order. A
+// variable referenced nowhere is a warning. The variables of Perses, such as $__rate_interval, are always
+// defined.
+func LintVariables(d v1.Dashboard) ([]Issue, error) {
+	d, err := perses.Normalize(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	add := func(severity Severity, object string, format string, args ...any) {
+		issues = append(issues, Issue{
+			Severity:  severity,
+			Dashboard: d.Metadata.Name,
+			Object:    object,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	index := map[string]int{}
+	for i, v := range d.Spec.Variables {
+		index[v.Spec.GetName()] = i
+	}
+	used := map[string]bool{}
+	check := func(object string, position int, references []string) {
+		for _, name := range references {
+			used[name] = true
+			i, ok := index[name]
+			switch {
+			case strings.HasPrefix(name, "__"):
+			case !ok:
+				add(Error, object, "undefined variable %q", name)
+			case i >= position:
+				add(Error, object, "variable %q is referenced before it is defined", name)
+			}
+		}
+	}
+
+	for i, v := range d.Spec.Variables {
+		list, ok := v.Spec.(*dashboard.ListVariableSpec)
+		if !ok {
+			continue
+		}
+		references, err := variableReferences(list)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", list.Name, err)
+		}
+		check(fmt.Sprintf("variable %q", list.Name), i, references)
+	}
+
+	panels, err := perses.Panels(d)
+	if err != nil {
+		return nil, err
+	}
+	for _, panel := range panels {
+		queries, err := perses.PanelQueries(panel.Panel)
+		if err != nil {
+			return nil, fmt.Errorf("panel %q: %w", panel.Name(), err)
+		}
+		for i, query := range queries {
+			check(fmt.Sprintf("panel %q query %d", panel.Name(), i+1), len(d.Spec.Variables),
+				references(query.Datasource, query.Query))
+		}
+	}
+
+	for _, v := range d.Spec.Variables {
+		if name := v.Spec.GetName(); !used[name] {
+			add(Warning, fmt.Sprintf("variable %q", name), "variable is never used")
+		}
+	}
+	return issues, nil
+}
+
+// variableReferences returns the variables referenced by the datasource and the queries of a Prometheus
+// variable, and nothing for the variables of other plugins.
+func variableReferences(list *dashboard.ListVariableSpec) ([]string, error) {
+	var spec struct {
+		Datasource *datasource.Selector `json:"datasource"`
+		Matchers   []string             `json:"matchers"`
+		Expr       string               `json:"expr"`
+	}
+	switch list.Plugin.Kind {
+	case labelValuesVar.PluginKind, labelNamesVar.PluginKind, promqlVar.PluginKind:
+		if err := perses.DecodePluginSpec(list.Plugin, &spec); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return references(spec.Datasource, append(spec.Matchers, spec.Expr)...), nil
+}
+
+// references returns the variables referenced by the name of the datasource and the queries.
+func references(selector *datasource.Selector, queries ...string) []string {
+	var names []string
+	if selector != nil {
+		names = append(names, promql.Variables(selector.Name)...)
+	}
+	for _, query := range queries {
+		names = append(names, promql.Variables(query)...)
+	}
+	return names
+}
@@ -0,0 +1,230 @@
+package perses
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const (
+	// TokenEnv and PasswordEnv hold the credentials of the Perses API when the flags are not set, so that
+	// they do not show in the command line.
+	TokenEnv    = "PERSES_TOKEN"
+	PasswordEnv = "PERSES_PASSWORD"
+)
+
+// ClientConfig holds the address, credentials and TLS settings of a Perses API server.
+type ClientConfig struct {
+	URL                string
+	Token              string
+	Username           string
+	Password           string
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// WithEnv returns the configuration with the token and the password read from TokenEnv and PasswordEnv when
+// they are not set.
+func (c ClientConfig) WithEnv() ClientConfig {
+	if c.Token == "" {
+		c.Token = os.Getenv(TokenEnv)
+	}
+	if c.Password == "" {
+		c.Password = os.Getenv(PasswordEnv)
+	}
+	return c
+}
+
+// HTTPClient returns an HTTP client using the TLS settings of the configuration.
+func (c ClientConfig) HTTPClient() (*http.Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CAFile != "" {
+		ca, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %q", c.CAFile)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
+
+// Client talks to the dashboards and projects endpoints of the Perses API.
+type Client struct {
+	url        *url.URL
+	config     ClientConfig
+	httpClient *http.Client
+	projects   map[string]bool
+}
+
+// NewClient returns a client of the Perses API at config.URL. The HTTP client is the one built from the
+// configuration when nil, tests passing the client of their httptest server instead.
+func NewClient(config ClientConfig, httpClient *http.Client) (*Client, error) {
+	if config.URL == "" {
+		return nil, errors.New("--perses-url is required")
+	}
+	apiURL, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Perses URL: %w", err)
+	}
+	if apiURL.Scheme != "http" && apiURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid Perses URL %q: the scheme must be http or https", config.URL)
+	}
+	if config.Token != "" && config.Username != "" {
+		return nil, errors.New("--perses-token and --perses-username are mutually exclusive")
+	}
+	if httpClient == nil {
+		if httpClient, err = config.HTTPClient(); err != nil {
+			return nil, err
+		}
+	}
+	return &Client{
+		url:        apiURL,
+		config:     config,
+		httpClient: httpClient,
+		projects:   map[string]bool{},
+	}, nil
+}
+
+// APIError is returned when the Perses API answers with an unexpected status code.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// ApplyDashboard creates the dashboard in its project, or updates it when it already exists. The project
+// is created first when missing.
+func (c *Client) ApplyDashboard(ctx context.Context, dashboard v1.Dashboard) error {
+	project := dashboard.Metadata.Project
+	if err := c.ensureProject(ctx, project); err != nil {
+		return err
+	}
+
+	path := "/api/v1/projects/" + url.PathEscape(project) + "/dashboards"
+	err := c.do(ctx, http.MethodPost, path, dashboard, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		err = c.do(ctx, http.MethodPut, path+"/"+url.PathEscape(dashboard.Metadata.Name), dashboard, nil)
+	}
+	return err
+}
+
+// GetDashboard returns the dashboard of the project, and false when the project or the dashboard does not exist.
+func (c *Client) GetDashboard(ctx context.Context, project string, name string) (v1.Dashboard, bool, error) {
+	var dashboard v1.Dashboard
+	err := c.do(ctx, http.MethodGet, "/api/v1/projects/"+url.PathEscape(project)+"/dashboards/"+url.PathEscape(name), nil, &dashboard)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return v1.Dashboard{}, false, nil
+	}
+	if err != nil {
+		return v1.Dashboard{}, false, err
+	}
+	return dashboard, true, nil
+}
+
+func (c *Client) ensureProject(ctx context.Context, project string) error {
+	if c.projects[project] {
+		return nil
+	}
+	err := c.do(ctx, http.MethodGet, "/api/v1/projects/"+url.PathEscape(project), nil, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		err = c.do(ctx, http.MethodPost, "/api/v1/projects", v1.Project{
+			Kind:     v1.KindProject,
+			Metadata: v1.Metadata{Name: project},
+		}, nil)
+	}
+	if err != nil {
+		return err
+	}
+	c.projects[project] = true
+	return nil
+}
+
+// do sends the request body as JSON and decodes the response into result when not nil.
+func (c *Client) do(ctx context.Context, method string, path string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.url.JoinPath(path).String(), reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.config.Token)
+	} else if c.config.Username != "" {
+		request.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return &APIError{Method: method, Path: path, StatusCode: response.StatusCode, Message: errorMessage(data)}
+	}
+	if result != nil {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
+
+// errorMessage returns the message of a Perses API error, which is JSON encoded as {"message": "..."}.
+func errorMessage(data []byte) string {
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &body); err == nil && body.Message != "" {
+		return body.Message
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,203 @@
+package perses
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+// fakeServer is a stand-in for the projects and dashboards endpoints of the Perses API.
+type fakeServer struct {
+	mu         sync.Mutex
+	auth       func(*http.Request) bool
+	projects   map[string]bool
+	dashboards map[string]v1.Dashboard
+	requests   []string
+}
+
+func newFakeServer(auth func(*http.Request) bool) *fakeServer {
+	return &fakeServer{auth: auth, projects: map[string]bool{}, dashboards: map[string]v1.Dashboard{}}
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if s.auth != nil && !s.auth(r) {
+		writeError(w, http.StatusUnauthorized, "missing or invalid credentials")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "projects":
+		if !s.projects[parts[1]] {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "projects":
+		var project v1.Project
+		if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.projects[project.Metadata.Name] = true
+		w.WriteHeader(http.StatusOK)
+	case (r.Method == http.MethodPost && len(parts) == 3 || r.Method == http.MethodPut && len(parts) == 4) && parts[2] == "dashboards":
+		var dashboard v1.Dashboard
+		if err := json.NewDecoder(r.Body).Decode(&dashboard); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		key := parts[1] + "/" + dashboard.Metadata.Name
+		_, exists := s.dashboards[key]
+		if r.Method == http.MethodPost && exists {
+			writeError(w, http.StatusConflict, "document already exists")
+			return
+		}
+		if r.Method == http.MethodPut && (!exists || parts[3] != dashboard.Metadata.Name) {
+			writeError(w, http.StatusNotFound, "document not found")
+			return
+		}
+		s.dashboards[key] = dashboard
+		w.WriteHeader(http.StatusOK)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func testDashboard(project string, name string) v1.Dashboard {
+	return v1.Dashboard{
+		Kind: v1.KindDashboard,
+		Metadata: v1.ProjectMetadata{
+			Metadata:               v1.Metadata{Name: name},
+			ProjectMetadataWrapper: v1.ProjectMetadataWrapper{Project: project},
+		},
+		Spec: v1.DashboardSpec{Panels: map[string]*v1.Panel{}, Layouts: []dashboard.Layout{}},
+	}
+}
+
+func TestApplyDashboardCreatesThenUpdates(t *testing.T) {
+	fake := newFakeServer(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer secret"
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{URL: server.URL, Token: "secret"}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := client.ApplyDashboard(context.Background(), testDashboard("monitoring", "overview")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"GET /api/v1/projects/monitoring",
+		"POST /api/v1/projects",
+		"POST /api/v1/projects/monitoring/dashboards",
+		"POST /api/v1/projects/monitoring/dashboards",
+		"PUT /api/v1/projects/monitoring/dashboards/overview",
+	}
+	if strings.Join(fake.requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(fake.requests, "\n"), strings.Join(want, "\n"))
+	}
+	if _, ok := fake.dashboards["monitoring/overview"]; !ok {
+		t.Error("dashboard monitoring/overview not stored")
+	}
+}
+
+func TestApplyDashboardBasicAuth(t *testing.T) {
+	fake := newFakeServer(func(r *http.Request) bool {
+		username, password, ok := r.BasicAuth()
+		return ok && username == "admin" && password == "password"
+	})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{URL: server.URL, Username: "admin", Password: "password"}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ApplyDashboard(context.Background(), testDashboard("default", "overview")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyDashboardError(t *testing.T) {
+	server := httptest.NewServer(newFakeServer(func(*http.Request) bool { return false }))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{URL: server.URL}, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.ApplyDashboard(context.Background(), testDashboard("default", "overview"))
+	if err == nil || !strings.Contains(err.Error(), "401 Unauthorized: missing or invalid credentials") {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestApplyDashboardTLS(t *testing.T) {
+	fake := newFakeServer(nil)
+	server := httptest.NewTLSServer(fake)
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		config  ClientConfig
+		wantErr bool
+	}{
+		{name: "unknown CA", config: ClientConfig{URL: server.URL}, wantErr: true},
+		{name: "CA file", config: ClientConfig{URL: server.URL, CAFile: caFile}},
+		{name: "insecure skip verify", config: ClientConfig{URL: server.URL, InsecureSkipVerify: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewClient(tc.config, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = client.ApplyDashboard(context.Background(), testDashboard("default", "overview"))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewClientValidation(t *testing.T) {
+	for _, config := range []ClientConfig{
+		{},
+		{URL: "localhost:8080"},
+		{URL: "http://localhost:8080", Token: "secret", Username: "admin"},
+	} {
+		if _, err := NewClient(config, nil); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package perses
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+type ChangeType string
+
+const (
+	Added   ChangeType = "+"
+	Removed ChangeType = "-"
+	Changed ChangeType = "~"
+)
+
+// Change is a difference between two versions of a dashboard, on the dashboard itself, a variable or a panel.
+type Change struct {
+	Type ChangeType
+	// Object names what changed, such as `panel "CPU / CPU Usage"` or `variable "job"`.
+	Object string
+	// Details lists what changed in the object.
+	Details []string
+}
+
+func (c Change) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", c.Type, c.Object)
+	for _, detail := range c.Details {
+		sb.WriteString("\n    ")
+		sb.WriteString(strings.ReplaceAll(detail, "\n", "\n    "))
+	}
+	return sb.String()
+}
+
+// LayoutPanel is a panel of a dashboard along with the title of its panel group and its grid position.
+type LayoutPanel struct {
+	Group string
+	Item  dashboard.GridItem
+	Panel *v1.Panel
+}
+
+// Name returns the group and panel titles, which identify a panel better than its key in the dashboard
+// since the SDK numbers the panels by position.
+func (p LayoutPanel) Name() string {
+	if p.Group == "" {
+		return p.Panel.Spec.Display.Name
+	}
+	return p.Group + " / " + p.Panel.Spec.Display.Name
+}
+
+// Panels returns the panels of a normalized dashboard in layout order.
+func Panels(d v1.Dashboard) ([]LayoutPanel, error) {
+	var panels []LayoutPanel
+	for i, layout := range d.Spec.Layouts {
+		grid, ok := layout.Spec.(*dashboard.GridLayoutSpec)
+		if !ok {
+			return nil, fmt.Errorf("layout %d: unsupported layout %q", i, layout.Kind)
+		}
+		group := ""
+		if grid.Display != nil {
+			group = grid.Display.Title
+		}
+		for _, item := range grid.Items {
+			if item.Content == nil {
+				return nil, fmt.Errorf("layout %d: grid item without content", i)
+			}
+			panel, ok := d.Spec.Panels[strings.TrimPrefix(item.Content.Ref, "#/spec/panels/")]
+			if !ok {
+				return nil, fmt.Errorf("layout %d: unknown panel %q", i, item.Content.Ref)
+			}
+			panels = append(panels, LayoutPanel{Group: group, Item: item, Panel: panel})
+		}
+	}
+	return panels, nil
+}
+
+// Diff compares two versions of a dashboard and returns the panels and variables added, removed or changed,
+// in the order of the new version. Panels are matched by group and title and variables by name.
+func Diff(oldDashboard v1.Dashboard, newDashboard v1.Dashboard) ([]Change, error) {
+	oldDashboard, err := Normalize(oldDashboard)
+	if err != nil {
+		return nil, err
+	}
+	newDashboard, err = Normalize(newDashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	if details := diffValues(dashboardSettings(oldDashboard), dashboardSettings(newDashboard)); len(details) > 0 {
+		changes = append(changes, Change{Type: Changed, Object: "dashboard", Details: details})
+	}
+
+	oldVariables, newVariables := variablesByName(oldDashboard), variablesByName(newDashboard)
+	for _, v := range newDashboard.Spec.Variables {
+		name := v.Spec.GetName()
+		object := fmt.Sprintf("variable %q", name)
+		oldVariable, ok := oldVariables[name]
+		if !ok {
+			changes = append(changes, Change{Type: Added, Object: object})
+			continue
+		}
+		if details := diffValues(oldVariable, v); len(details) > 0 {
+			changes = append(changes, Change{Type: Changed, Object: object, Details: details})
+		}
+	}
+	for _, v := range oldDashboard.Spec.Variables {
+		if _, ok := newVariables[v.Spec.GetName()]; !ok {
+			changes = append(changes, Change{Type: Removed, Object: fmt.Sprintf("variable %q", v.Spec.GetName())})
+		}
+	}
+
+	oldPanels, err := panelsByName(oldDashboard)
+	if err != nil {
+		return nil, err
+	}
+	newPanels, err := panelsByName(newDashboard)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range newPanels.names {
+		object := fmt.Sprintf("panel %q", name)
+		oldPanel, ok := oldPanels.panels[name]
+		if !ok {
+			changes = append(changes, Change{Type: Added, Object: object})
+			continue
+		}
+		details, err := diffPanels(oldPanel, newPanels.panels[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", object, err)
+		}
+		if len(details) > 0 {
+			changes = append(changes, Change{Type: Changed, Object: object, Details: details})
+		}
+	}
+	for _, name := range oldPanels.names {
+		if _, ok := newPanels.panels[name]; !ok {
+			changes = append(changes, Change{Type: Removed, Object: fmt.Sprintf("panel %q", name)})
+		}
+	}
+	return changes, nil
+}
+
+// dashboardSettings returns the settings of the dashboard other than its variables, panels and layouts.
+func dashboardSettings(d v1.Dashboard) map[string]any {
+	return map[string]any{
+		"display":         d.Spec.Display,
+		"datasources":     d.Spec.Datasources,
+		"duration":        d.Spec.Duration,
+		"refreshInterval": d.Spec.RefreshInterval,
+	}
+}
+
+func variablesByName(d v1.Dashboard) map[string]dashboard.Variable {
+	variables := map[string]dashboard.Variable{}
+	for _, v := range d.Spec.Variables {
+		variables[v.Spec.GetName()] = v
+	}
+	return variables
+}
+
+type namedPanels struct {
+	names  []string
+	panels map[string]LayoutPanel
+}
+
+// panelsByName indexes the panels by name, numbering the panels sharing the name of a previous one.
+func panelsByName(d v1.Dashboard) (namedPanels, error) {
+	panels, err := Panels(d)
+	if err != nil {
+		return namedPanels{}, err
+	}
+	result := namedPanels{panels: map[string]LayoutPanel{}}
+	for _, panel := range panels {
+		name := panel.Name()
+		for i := 2; ; i++ {
+			if _, ok := result.panels[name]; !ok {
+				break
+			}
+			name = fmt.Sprintf("%s #%d", panel.Name(), i)
+		}
+		result.names = append(result.names, name)
+		result.panels[name] = panel
+	}
+	return result, nil
+}
+
+func diffPanels(oldPanel LayoutPanel, newPanel LayoutPanel) ([]string, error) {
+	var details []string
+
+	if oldPanel.Panel.Spec.Display.Description != newPanel.Panel.Spec.Display.Description {
+		details = append(details, fmt.Sprintf("description: %q -> %q", oldPanel.Panel.Spec.Display.Description, newPanel.Panel.Spec.Display.Description))
+	}
+	oldItem, newItem := oldPanel.Item, newPanel.Item
+	if oldItem.X != newItem.X || oldItem.Y != newItem.Y || oldItem.Width != newItem.Width || oldItem.Height != newItem.Height {
+		details = append(details, fmt.Sprintf("position: x=%d y=%d %dx%d -> x=%d y=%d %dx%d",
+			oldItem.X, oldItem.Y, oldItem.Width, oldItem.Height, newItem.X, newItem.Y, newItem.Width, newItem.Height))
+	}
+	details = append(details, diffValues(oldPanel.Panel.Spec.Plugin, newPanel.Panel.Spec.Plugin)...)
+
+	oldQueries, err := PanelQueries(oldPanel.Panel)
+	if err != nil {
+		return nil, err
+	}
+	newQueries, err := PanelQueries(newPanel.Panel)
+	if err != nil {
+		return nil, err
+	}
+	for i := range max(len(oldQueries), len(newQueries)) {
+		switch {
+		case i >= len(oldQueries):
+			details = append(details, fmt.Sprintf("query %d added:\n%s", i+1, prefixLines("+ ", newQueries[i].Query)))
+		case i >= len(newQueries):
+			details = append(details, fmt.Sprintf("query %d removed:\n%s", i+1, prefixLines("- ", oldQueries[i].Query)))
+		default:
+			oldQuery, newQuery := oldQueries[i], newQueries[i]
+			if oldQuery.Query != newQuery.Query {
+				details = append(details, fmt.Sprintf("query %d:\n%s\n%s", i+1, prefixLines("- ", oldQuery.Query), prefixLines("+ ", newQuery.Query)))
+			}
+			oldQuery.Query, newQuery.Query = "", ""
+			for _, detail := range diffValues(oldQuery, newQuery) {
+				details = append(details, fmt.Sprintf("query %d %s", i+1, detail))
+			}
+		}
+	}
+	return details, nil
+}
+
+func prefixLines(prefix string, s string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+// diffValues compares the JSON encodings of two values field by field and returns a line per field that
+// differs, such as `spec.plugin.spec.matchers[0]: "up" -> "up{job=\"node\"}"`.
+func diffValues(oldValue any, newValue any) []string {
+	oldFields, newFields := map[string]string{}, map[string]string{}
+	flatten("", toJSONValue(oldValue), oldFields)
+	flatten("", toJSONValue(newValue), newFields)
+
+	keys := map[string]bool{}
+	for key := range oldFields {
+		keys[key] = true
+	}
+	for key := range newFields {
+		keys[key] = true
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var details []string
+	for _, key := range sortedKeys {
+		oldField, oldOk := oldFields[key]
+		newField, newOk := newFields[key]
+		switch {
+		case !oldOk:
+			details = append(details, fmt.Sprintf("%s: %s added", key, newField))
+		case !newOk:
+			details = append(details, fmt.Sprintf("%s: %s removed", key, oldField))
+		case oldField != newField:
+			details = append(details, fmt.Sprintf("%s: %s -> %s", key, oldField, newField))
+		}
+	}
+	return details
+}
+
+func toJSONValue(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return string(data)
+	}
+	return result
+}
+
+// flatten stores the leaves of a decoded JSON value by path. Empty objects and arrays are left out, since
+// they are the same as missing ones to Perses.
+func flatten(path string, value any, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flatten(childPath, item, fields)
+		}
+	case []any:
+		for i, item := range v {
+			flatten(fmt.Sprintf("%s[%d]", path, i), item, fields)
+		}
+	default:
+		if v == nil || reflect.ValueOf(v).IsZero() {
+			return
+		}
+		data, _ := json.Marshal(v)
+		if path == "" {
+			path = "value"
+		}
+		fields[path] = string(data)
+	}
+}
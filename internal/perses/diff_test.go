@@ -0,0 +1,205 @@
+package perses
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perses/perses/go-sdk/dashboard"
+	"github.com/perses/perses/go-sdk/panel"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	timeSeriesPanel "github.com/perses/perses/go-sdk/panel/time-series"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+const usageQuery = `sum by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))`
+
+func usagePanel(expr string, format string) panelgroup.Option {
+	return panelgroup.AddPanel("Usage", timeSeriesPanel.Chart(), panel.AddQuery(query.PromQL(expr, query.SeriesNameFormat(format))))
+}
+
+func loadPanel() panelgroup.Option {
+	return panelgroup.AddPanel("Load", timeSeriesPanel.Chart(), panel.AddQuery(query.PromQL("node_load1")))
+}
+
+func jobVariable(matcher string) dashboard.Option {
+	return dashboard.AddVariable("job", listVar.List(labelValuesVar.PrometheusLabelValues("job", labelValuesVar.Matchers(matcher))))
+}
+
+func newDashboard(t *testing.T, options ...dashboard.Option) v1.Dashboard {
+	t.Helper()
+	builder, err := dashboard.New("node", append([]dashboard.Option{dashboard.ProjectName("default")}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return builder.Dashboard
+}
+
+func TestDiff(t *testing.T) {
+	current := newDashboard(t,
+		jobVariable("node_uname_info"),
+		dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel()),
+	)
+
+	for _, tc := range []struct {
+		name    string
+		options []dashboard.Option
+		// want lists the changes, without their details.
+		want []string
+		// details are expected in the details of the first change.
+		details []string
+	}{
+		{
+			name: "identical",
+			options: []dashboard.Option{
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel()),
+			},
+		},
+		{
+			name: "panel added",
+			options: []dashboard.Option{
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel(),
+					panelgroup.AddPanel("Steal", timeSeriesPanel.Chart(), panel.AddQuery(query.PromQL("node_cpu_seconds_total")))),
+			},
+			want: []string{`+ panel "CPU / Steal"`},
+		},
+		{
+			name: "panel removed",
+			options: []dashboard.Option{
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}")),
+			},
+			want: []string{`- panel "CPU / Load"`},
+		},
+		{
+			name: "panel moved to another group",
+			options: []dashboard.Option{
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}")),
+				dashboard.AddPanelGroup("System", loadPanel()),
+			},
+			want: []string{`+ panel "System / Load"`, `- panel "CPU / Load"`},
+		},
+		{
+			name: "query changed",
+			options: []dashboard.Option{
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(`sum by (instance) (rate(node_cpu_seconds_total{mode="user"}[5m]))`, "{{instance}}"), loadPanel()),
+			},
+			want: []string{`~ panel "CPU / Usage"`},
+			details: []string{
+				"query 1:\n- " + usageQuery + "\n+ sum by (instance) (rate(node_cpu_seconds_total{mode=\"user\"}[5m]))",
+			},
+		},
+		{
+			name: "series name changed",
+			options: []dashboard.Option{
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}} usage"), loadPanel()),
+			},
+			want:    []string{`~ panel "CPU / Usage"`},
+			details: []string{`query 1 seriesNameFormat: "{{instance}}" -> "{{instance}} usage"`},
+		},
+		{
+			name: "variable changed",
+			options: []dashboard.Option{
+				jobVariable(`node_uname_info{env="prod"}`),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel()),
+			},
+			want:    []string{`~ variable "job"`},
+			details: []string{`spec.plugin.spec.matchers[0]: "node_uname_info{}" -> "node_uname_info{env=\"prod\"}"`},
+		},
+		{
+			name: "variable added and removed",
+			options: []dashboard.Option{
+				dashboard.AddVariable("instance", listVar.List(labelValuesVar.PrometheusLabelValues("instance"))),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel()),
+			},
+			want: []string{`+ variable "instance"`, `- variable "job"`},
+		},
+		{
+			name: "dashboard settings changed",
+			options: []dashboard.Option{
+				dashboard.Duration(6 * time.Hour),
+				jobVariable("node_uname_info"),
+				dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel()),
+			},
+			want:    []string{"~ dashboard"},
+			details: []string{`duration: "1h" -> "6h"`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			changes, err := Diff(current, newDashboard(t, tc.options...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, change := range changes {
+				got = append(got, string(change.Type)+" "+change.Object)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("expected the changes %q, got %q", tc.want, got)
+			}
+			for _, detail := range tc.details {
+				if !slices.Contains(changes[0].Details, detail) {
+					t.Errorf("expected the detail %q, got %q", detail, changes[0].Details)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffDecodedDashboard(t *testing.T) {
+	// A dashboard read from a file holds maps where the SDK holds builders, which must not be reported.
+	built := newDashboard(t, jobVariable("node_uname_info"), dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}")))
+	decoded, err := Normalize(built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := Diff(decoded, built)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) > 0 {
+		t.Errorf("expected no change, got %v", changes)
+	}
+}
+
+func TestPanels(t *testing.T) {
+	d := newDashboard(t,
+		dashboard.AddPanelGroup("CPU", usagePanel(usageQuery, "{{instance}}"), loadPanel()),
+		dashboard.AddPanelGroup("Memory", loadPanel()),
+	)
+	normalized, err := Normalize(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	panels, err := Panels(normalized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range panels {
+		names = append(names, p.Name())
+	}
+	if want := []string{"CPU / Usage", "CPU / Load", "Memory / Load"}; !slices.Equal(names, want) {
+		t.Errorf("expected the panels %v in layout order, got %v", want, names)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	change := Change{Type: Changed, Object: `panel "CPU / Usage"`, Details: []string{"query 1:\n- up\n+ up{job=\"node\"}"}}
+	want := "~ panel \"CPU / Usage\"\n    query 1:\n    - up\n    + up{job=\"node\"}"
+	if got := change.String(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	if !strings.HasPrefix(Change{Type: Added, Object: "dashboard"}.String(), "+ dashboard") {
+		t.Error("unexpected added dashboard change")
+	}
+}
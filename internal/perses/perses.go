@@ -0,0 +1,56 @@
+package perses
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+	"github.com/perses/perses/pkg/model/api/v1/common"
+)
+
+// Normalize returns a copy of the dashboard decoded from its JSON encoding. A dashboard built with the
+// SDK holds its layouts and variables as values and the plugin builders as specs, while a decoded one
+// holds pointers and maps, so normalizing gives both the same shape.
+func Normalize(dashboard v1.Dashboard) (v1.Dashboard, error) {
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return v1.Dashboard{}, fmt.Errorf("unable to encode dashboard: %w", err)
+	}
+	var normalized v1.Dashboard
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return v1.Dashboard{}, fmt.Errorf("unable to decode dashboard: %w", err)
+	}
+	return normalized, nil
+}
+
+// DecodePluginSpec decodes the spec of a plugin into spec, usually the PluginSpec type of the SDK package
+// of the plugin. The spec of a plugin holds the SDK builder when the dashboard was built in Go and a map
+// when it was decoded from a file, so it goes through JSON in both cases.
+func DecodePluginSpec(plugin common.Plugin, spec any) error {
+	data, err := json.Marshal(plugin.Spec)
+	if err != nil {
+		return fmt.Errorf("unable to encode %s spec: %w", plugin.Kind, err)
+	}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return fmt.Errorf("unable to decode %s spec: %w", plugin.Kind, err)
+	}
+	return nil
+}
+
+// PanelQueries returns the Prometheus queries of a panel, with their expression, series name format
+// and datasource. It fails on queries of another plugin.
+func PanelQueries(panel *v1.Panel) ([]query.PluginSpec, error) {
+	queries := make([]query.PluginSpec, 0, len(panel.Spec.Queries))
+	for _, q := range panel.Spec.Queries {
+		if q.Spec.Plugin.Kind != query.PluginKind {
+			return nil, fmt.Errorf("unsupported query plugin %q", q.Spec.Plugin.Kind)
+		}
+		var spec query.PluginSpec
+		if err := DecodePluginSpec(q.Spec.Plugin, &spec); err != nil {
+			return nil, err
+		}
+		queries = append(queries, spec)
+	}
+	return queries, nil
+}
@@ -1,45 +1,78 @@
 package promql
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql/parser"
 )
 
+// LabelMatcher is a label matcher of a PromQL selector, such as job=~"node.*", given by the label name, the
+// match type, one of =, !=, =~ and !~, and the value. pkg/promql exposes it to the public builders.
 type LabelMatcher struct {
 	Name  string
 	Value string
 	Type  string
 }
 
-func SetLabelMatchers(query string, labelMatchers []LabelMatcher) string {
-	for _, l := range labelMatchers {
-		query = LabelsSetPromQL(query, l.Type, l.Name, l.Value)
+// MatchType returns the match type of the label matcher, or an error naming the label when the type is none
+// of =, !=, =~ and !~.
+func (l LabelMatcher) MatchType() (labels.MatchType, error) {
+	switch l.Type {
+	case parser.ItemType(parser.EQL).String():
+		return labels.MatchEqual, nil
+	case parser.ItemType(parser.NEQ).String():
+		return labels.MatchNotEqual, nil
+	case parser.ItemType(parser.EQL_REGEX).String():
+		return labels.MatchRegexp, nil
+	case parser.ItemType(parser.NEQ_REGEX).String():
+		return labels.MatchNotRegexp, nil
 	}
-	return query
+	return 0, fmt.Errorf("unknown match type %q for label %q", l.Type, l.Name)
 }
 
-func LabelsSetPromQL(query, labelMatchType, name, value string) string {
-	expr, err := parser.ParseExpr(query)
+// SetLabelMatchers parses the query and injects every label matcher in each of its vector selectors.
+// The query may reference Perses variables, see ParseExpr. It returns an error naming the expression
+// when the query does not parse or a matcher is invalid.
+func SetLabelMatchers(query string, labelMatchers []LabelMatcher) (string, error) {
+	expr, err := ParseExpr(query)
 	if err != nil {
-		return ""
+		return "", fmt.Errorf("invalid PromQL expression %q: %w", query, err)
 	}
 
-	if name == "" || value == "" {
-		return expr.Pretty(0)
+	if err := expr.SetLabelMatchers(labelMatchers); err != nil {
+		return "", fmt.Errorf("unable to set label matcher on %q: %w", query, err)
 	}
+	return expr.String(), nil
+}
 
-	var matchType labels.MatchType
-	switch labelMatchType {
-	case parser.ItemType(parser.EQL).String():
-		matchType = labels.MatchEqual
-	case parser.ItemType(parser.NEQ).String():
-		matchType = labels.MatchNotEqual
-	case parser.ItemType(parser.EQL_REGEX).String():
-		matchType = labels.MatchRegexp
-	case parser.ItemType(parser.NEQ_REGEX).String():
-		matchType = labels.MatchNotRegexp
-	default:
-		return ""
+// SetLabelMatchers injects every label matcher in each vector selector of the expression, replacing the
+// matchers the selectors already have on the same labels.
+func (e *Expr) SetLabelMatchers(labelMatchers []LabelMatcher) error {
+	for _, l := range labelMatchers {
+		if err := setLabelMatcher(e.AST, l.Type, l.Name, l.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func LabelsSetPromQL(query, labelMatchType, name, value string) (string, error) {
+	return SetLabelMatchers(query, []LabelMatcher{{Name: name, Value: value, Type: labelMatchType}})
+}
+
+func setLabelMatcher(expr parser.Expr, labelMatchType, name, value string) error {
+	// The match type is checked first so that a matcher with an invalid type fails even when it is skipped.
+	matchType, err := LabelMatcher{Name: name, Type: labelMatchType}.MatchType()
+	if err != nil {
+		return err
+	}
+	if name == "" || value == "" {
+		return nil
 	}
 
 	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
@@ -62,5 +95,198 @@ func LabelsSetPromQL(query, labelMatchType, name, value string) string {
 		}
 		return nil
 	})
-	return expr.Pretty(0)
+	return nil
+}
+
+// SetGroupingLabels parses the query and adds the labels to the by clause of every aggregation and to the
+// on clause of every binary operation, removing them from the without and ignoring clauses, so that series
+// differing by the labels, such as the series of two clusters, are neither aggregated nor matched together.
+// Aggregations and binary operations without by or on labels are left untouched.
+func SetGroupingLabels(query string, groupingLabels []string) (string, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid PromQL expression %q: %w", query, err)
+	}
+
+	expr.SetGroupingLabels(groupingLabels)
+	return expr.String(), nil
+}
+
+// SetGroupingLabels adds the labels to the by and on clauses of the expression and removes them from its
+// without and ignoring clauses, see the SetGroupingLabels function.
+func (e *Expr) SetGroupingLabels(groupingLabels []string) {
+	parser.Inspect(e.AST, func(node parser.Node, path []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.AggregateExpr:
+			if n.Without {
+				n.Grouping = removeLabels(n.Grouping, groupingLabels)
+			} else if len(n.Grouping) > 0 {
+				n.Grouping = addLabels(n.Grouping, groupingLabels)
+			}
+		case *parser.BinaryExpr:
+			if n.VectorMatching == nil {
+				break
+			}
+			if !n.VectorMatching.On {
+				n.VectorMatching.MatchingLabels = removeLabels(n.VectorMatching.MatchingLabels, groupingLabels)
+			} else if len(n.VectorMatching.MatchingLabels) > 0 {
+				n.VectorMatching.MatchingLabels = addLabels(n.VectorMatching.MatchingLabels, groupingLabels)
+				// A label cannot be both matched on and copied by group_left or group_right.
+				n.VectorMatching.Include = removeLabels(n.VectorMatching.Include, groupingLabels)
+			}
+		}
+		return nil
+	})
+}
+
+func addLabels(labels []string, added []string) []string {
+	for _, l := range added {
+		if !slices.Contains(labels, l) {
+			labels = append(labels, l)
+		}
+	}
+	return labels
+}
+
+func removeLabels(labels []string, removed []string) []string {
+	return slices.DeleteFunc(labels, func(l string) bool {
+		return slices.Contains(removed, l)
+	})
+}
+
+// RemoveFallbacks parses the query and replaces every `or` one of whose operands only selects dropped
+// metrics with its other operand, for instance to keep only the name a renamed metric has in the version of
+// the exporter, given `old or new`.
+func RemoveFallbacks(query string, dropped func(metric string) bool) (string, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid PromQL expression %q: %w", query, err)
+	}
+	expr.AST = removeFallbacks(expr.AST, dropped)
+	return expr.String(), nil
+}
+
+func removeFallbacks(expr parser.Expr, dropped func(metric string) bool) parser.Expr {
+	switch n := expr.(type) {
+	case *parser.BinaryExpr:
+		n.LHS = removeFallbacks(n.LHS, dropped)
+		n.RHS = removeFallbacks(n.RHS, dropped)
+		if n.Op == parser.LOR {
+			lhsDropped, rhsDropped := selectsOnly(n.LHS, dropped), selectsOnly(n.RHS, dropped)
+			if lhsDropped && !rhsDropped {
+				return n.RHS
+			}
+			if rhsDropped && !lhsDropped {
+				return n.LHS
+			}
+		}
+	case *parser.ParenExpr:
+		_, wasBinary := n.Expr.(*parser.BinaryExpr)
+		n.Expr = removeFallbacks(n.Expr, dropped)
+		// The parentheses of a removed `or` are no longer needed, unless the remaining operand is an operation.
+		if _, isBinary := n.Expr.(*parser.BinaryExpr); wasBinary && !isBinary {
+			return n.Expr
+		}
+	case *parser.AggregateExpr:
+		n.Expr = removeFallbacks(n.Expr, dropped)
+	case *parser.Call:
+		for i, arg := range n.Args {
+			n.Args[i] = removeFallbacks(arg, dropped)
+		}
+	case *parser.SubqueryExpr:
+		n.Expr = removeFallbacks(n.Expr, dropped)
+	case *parser.UnaryExpr:
+		n.Expr = removeFallbacks(n.Expr, dropped)
+	}
+	return expr
+}
+
+// selectsOnly tells whether the expression selects metrics, all of them dropped.
+func selectsOnly(expr parser.Expr, dropped func(metric string) bool) bool {
+	found, all := false, true
+	parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+		if n, ok := node.(*parser.VectorSelector); ok {
+			found = true
+			all = all && n.Name != "" && dropped(n.Name)
+		}
+		return nil
+	})
+	return found && all
+}
+
+// SetRangeInterval parses the query and sets the range of every rate, irate and increase, such as the [5m]
+// of rate(x[5m]), to interval. The interval is either a duration like 1m or a Perses variable like
+// $__rate_interval. The ranges of the other functions, such as max_over_time(x[1h]), and the subquery ranges
+// are left untouched.
+func SetRangeInterval(query string, interval string) (string, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid PromQL expression %q: %w", query, err)
+	}
+
+	if err := expr.SetRangeInterval(interval); err != nil {
+		return "", err
+	}
+	return expr.String(), nil
+}
+
+// rateFunctions are the functions whose range is the rate interval.
+var rateFunctions = []string{"rate", "irate", "increase"}
+
+// SetRangeInterval sets the range of every rate, irate and increase of the expression to interval, a
+// duration or a variable standing for one, see the SetRangeInterval function.
+func (e *Expr) SetRangeInterval(interval string) error {
+	rangeDuration, err := e.Duration(interval)
+	if err != nil {
+		return err
+	}
+
+	parser.Inspect(e.AST, func(node parser.Node, path []parser.Node) error {
+		n, ok := node.(*parser.Call)
+		if !ok || !slices.Contains(rateFunctions, n.Func.Name) {
+			return nil
+		}
+		if selector, ok := n.Args[0].(*parser.MatrixSelector); ok {
+			selector.Range = rangeDuration
+		}
+		return nil
+	})
+	return nil
+}
+
+// Duration returns the duration of the interval, a duration such as 5m or a variable such as
+// $__rate_interval, for which it returns the placeholder standing for the variable in the expression.
+func (e *Expr) Duration(interval string) (time.Duration, error) {
+	duration := interval
+	if strings.HasPrefix(interval, "$") {
+		duration = e.DurationPlaceholder(interval)
+	}
+	d, err := model.ParseDuration(duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid range interval %q: %w", interval, err)
+	}
+	return time.Duration(d), nil
+}
+
+// ParseSelector parses a series selector such as job=~"node.*" or {job="node",env="prod"} into label
+// matchers. An empty selector returns no matcher.
+func ParseSelector(selector string) ([]LabelMatcher, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(selector, "{") {
+		selector = "{" + selector + "}"
+	}
+
+	matchers, err := parser.ParseMetricSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+
+	labelMatchers := make([]LabelMatcher, 0, len(matchers))
+	for _, m := range matchers {
+		labelMatchers = append(labelMatchers, LabelMatcher{Name: m.Name, Value: m.Value, Type: m.Type.String()})
+	}
+	return labelMatchers, nil
 }
@@ -0,0 +1,184 @@
+package promql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetRangeInterval(t *testing.T) {
+	for _, tc := range []struct {
+		query    string
+		interval string
+		want     string
+	}{
+		{
+			query:    `rate(node_cpu_seconds_total[5m])`,
+			interval: "$__rate_interval",
+			want:     `rate(node_cpu_seconds_total[$__rate_interval])`,
+		},
+		{
+			query:    `sum(irate(node_cpu_seconds_total[5m])) / sum(increase(node_cpu_seconds_total[1h]))`,
+			interval: "1m",
+			want:     `sum(irate(node_cpu_seconds_total[1m])) / sum(increase(node_cpu_seconds_total[1m]))`,
+		},
+		{
+			query:    `max_over_time(shards_desired[1h]) > rate(shards_max[5m])`,
+			interval: "$interval",
+			want:     `max_over_time(shards_desired[1h]) > rate(shards_max[$interval])`,
+		},
+		{
+			query:    `predict_linear(node_filesystem_avail_bytes[6h], 3600)`,
+			interval: "$interval",
+			want:     `predict_linear(node_filesystem_avail_bytes[6h], 3600)`,
+		},
+		{
+			query:    `max_over_time(rate(node_cpu_seconds_total[5m])[1h:5m])`,
+			interval: "$interval",
+			want:     `max_over_time(rate(node_cpu_seconds_total[$interval])[1h:5m])`,
+		},
+	} {
+		got, err := SetRangeInterval(tc.query, tc.interval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("expected %s, got %s", tc.want, got)
+		}
+	}
+
+	if _, err := SetRangeInterval(`rate(up[5m])`, "5 minutes"); err == nil {
+		t.Error("expected an error for an invalid interval")
+	}
+}
+
+func TestSetGroupingLabels(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "by",
+			query: `sum by (job) (up)`,
+			want:  `sum by (job, cluster, region) (up)`,
+		},
+		{
+			name:  "by with one of the labels",
+			query: `sum by (cluster, job) (up)`,
+			want:  `sum by (cluster, job, region) (up)`,
+		},
+		{
+			name:  "without",
+			query: `sum without (cluster, instance) (up)`,
+			want:  `sum without (instance) (up)`,
+		},
+		{
+			name:  "aggregation of every series",
+			query: `sum(up)`,
+			want:  `sum(up)`,
+		},
+		{
+			name:  "on",
+			query: `up / on (instance) up`,
+			want:  `up / on (instance, cluster, region) up`,
+		},
+		{
+			name:  "ignoring",
+			query: `up / ignoring (cluster, mode) up`,
+			want:  `up / ignoring (mode) up`,
+		},
+		{
+			name:  "on with group_left",
+			query: `up * on (instance) group_left (cluster, version) info`,
+			want:  `up * on (instance, cluster, region) group_left (version) info`,
+		},
+		{
+			name:  "ignoring with group_left",
+			query: `up * ignoring (cluster, mode) group_left (version) info`,
+			want:  `up * ignoring (mode) group_left (version) info`,
+		},
+		{
+			name:  "one-to-one matching",
+			query: `up / up`,
+			want:  `up / up`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SetGroupingLabels(tc.query, []string{"cluster", "region"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := SetGroupingLabels(`sum by (job (up)`, []string{"cluster"}); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
+
+func TestRemoveFallbacks(t *testing.T) {
+	dropped := func(metric string) bool { return strings.HasPrefix(metric, "old_") }
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "dropped left operand",
+			query: `old_samples_total or new_samples_total`,
+			want:  `new_samples_total`,
+		},
+		{
+			name:  "dropped right operand",
+			query: `new_samples_total or old_samples_total`,
+			want:  `new_samples_total`,
+		},
+		{
+			name:  "fallback in a function",
+			query: `sum by (job) (rate(old_samples_total[5m]) or rate(new_samples_total[5m]))`,
+			want:  `sum by (job) (rate(new_samples_total[5m]))`,
+		},
+		{
+			name:  "parentheses of the removed fallback",
+			query: `(old_samples_total or new_samples_total) / new_shards`,
+			want:  `new_samples_total / new_shards`,
+		},
+		{
+			name:  "parentheses of a remaining operation",
+			query: `(old_pending or (new_pending - new_sent)) / new_shards`,
+			want:  `(new_pending - new_sent) / new_shards`,
+		},
+		{
+			name:  "operand selecting kept metrics too",
+			query: `(old_samples_total + new_samples_total) or new_other_total`,
+			want:  `(old_samples_total + new_samples_total) or new_other_total`,
+		},
+		{
+			name:  "both operands dropped",
+			query: `old_samples_total or old_other_total`,
+			want:  `old_samples_total or old_other_total`,
+		},
+		{
+			name:  "other operators",
+			query: `old_samples_total and new_samples_total`,
+			want:  `old_samples_total and new_samples_total`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RemoveFallbacks(tc.query, dropped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := RemoveFallbacks(`old_samples_total or`, dropped); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
@@ -0,0 +1,294 @@
+package promql
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// variableRegexp matches the Perses variable syntaxes: $var, ${var} and ${var:format}.
+var variableRegexp = regexp.MustCompile(`^\$(?:\{(\w+)(?::(\w+))?\}|(\w+))`)
+
+// placeholderDurationBase is far enough from any realistic range or offset that the placeholders
+// standing for variables in durations cannot be mistaken for a duration written in the query.
+const placeholderDurationBase = 7919 * 24 * time.Hour
+
+// Expr is a parsed PromQL expression in which the Perses variables, such as $__rate_interval or $job,
+// were replaced by placeholders the Prometheus parser accepts. String restores the variables.
+type Expr struct {
+	AST parser.Expr
+
+	query        string
+	placeholders map[string]string
+	identifiers  map[string]string
+	durations    map[string]string
+}
+
+// ParseExpr parses a PromQL expression that may reference Perses variables. Variables inside string
+// literals are kept as they are, variables inside brackets or after offset are replaced by durations,
+// and any other variable, for instance in a by clause, is replaced by an identifier.
+func ParseExpr(query string) (*Expr, error) {
+	e := NewExpr(nil)
+	e.query = query
+
+	var sb strings.Builder
+	brackets := 0
+	for i := 0; i < len(query); {
+		switch c := query[i]; c {
+		case '"', '\'', '`':
+			end := stringLiteralEnd(query, i)
+			sb.WriteString(query[i:end])
+			i = end
+			continue
+		case '[':
+			brackets++
+		case ']':
+			brackets--
+		case '$':
+			if m := variableRegexp.FindString(query[i:]); m != "" {
+				if brackets > 0 || precededByOffset(query[:i]) {
+					sb.WriteString(e.DurationPlaceholder(m))
+				} else {
+					sb.WriteString(e.IdentifierPlaceholder(m))
+				}
+				i += len(m)
+				continue
+			}
+		}
+		sb.WriteByte(query[i])
+		i++
+	}
+
+	ast, err := parser.ParseExpr(sb.String())
+	if err != nil {
+		return nil, err
+	}
+	e.AST = ast
+	return e, nil
+}
+
+// NewExpr returns the expression of an AST built rather than parsed, holding no variable until
+// DurationPlaceholder gives one a placeholder, such as a range set by SetRangeInterval.
+func NewExpr(ast parser.Expr) *Expr {
+	return &Expr{
+		AST:          ast,
+		placeholders: map[string]string{},
+		identifiers:  map[string]string{},
+		durations:    map[string]string{},
+	}
+}
+
+// Clone returns a deep copy of the expression and its placeholders, so that updating the copy leaves the
+// expression untouched. The AST is formatted and parsed again, which only fails for an AST the parser
+// would not have returned, such as a rate of an aggregation.
+func (e *Expr) Clone() (*Expr, error) {
+	ast, err := parser.ParseExpr(e.AST.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to copy PromQL expression %q: %w", e.AST, err)
+	}
+	c := NewExpr(ast)
+	c.query = e.query
+	maps.Copy(c.placeholders, e.placeholders)
+	maps.Copy(c.identifiers, e.identifiers)
+	maps.Copy(c.durations, e.durations)
+	return c, nil
+}
+
+// Import returns the AST of the other expression, for instance to make it an operand of e, after replacing
+// in place its duration placeholders with the ones of e for the same variables, new ones being added to e
+// for the variables e lacks. The identifier placeholders are left as they are.
+func (e *Expr) Import(other *Expr) parser.Expr {
+	replaced := map[time.Duration]time.Duration{}
+	for variable, p := range other.durations {
+		from, _ := model.ParseDuration(p)
+		to, _ := model.ParseDuration(e.DurationPlaceholder(variable))
+		replaced[time.Duration(from)] = time.Duration(to)
+	}
+	replace := func(d *time.Duration) {
+		if to, ok := replaced[*d]; ok {
+			*d = to
+		}
+	}
+	parser.Inspect(other.AST, func(node parser.Node, path []parser.Node) error {
+		switch n := node.(type) {
+		case *parser.MatrixSelector:
+			replace(&n.Range)
+		case *parser.SubqueryExpr:
+			replace(&n.Range)
+			replace(&n.Step)
+			replace(&n.OriginalOffset)
+		case *parser.VectorSelector:
+			replace(&n.OriginalOffset)
+		}
+		return nil
+	})
+	return other.AST
+}
+
+// IdentifierPlaceholder returns the identifier standing for the variable in the expression,
+// to be used where PromQL expects a label or metric name.
+func (e *Expr) IdentifierPlaceholder(variable string) string {
+	if p, ok := e.identifiers[variable]; ok {
+		return p
+	}
+	p := fmt.Sprintf("__perses_var_%d__", len(e.placeholders))
+	e.identifiers[variable] = p
+	e.placeholders[p] = variable
+	return p
+}
+
+// DurationPlaceholder returns the duration standing for the variable in the expression,
+// to be used where PromQL expects a range, a subquery step or an offset.
+func (e *Expr) DurationPlaceholder(variable string) string {
+	if p, ok := e.durations[variable]; ok {
+		return p
+	}
+	for i := len(e.placeholders) + 1; ; i++ {
+		p := model.Duration(placeholderDurationBase + time.Duration(i)*time.Millisecond).String()
+		if _, used := e.placeholders[p]; used || strings.Contains(e.query, p) {
+			continue
+		}
+		e.durations[variable] = p
+		e.placeholders[p] = variable
+		return p
+	}
+}
+
+// Variable returns the variable a placeholder of the expression stands for, and false when s is not a
+// placeholder, for instance to tell a metric name given by a variable from a metric name.
+func (e *Expr) Variable(s string) (string, bool) {
+	variable, ok := e.placeholders[s]
+	return variable, ok
+}
+
+// HasVariables tells whether placeholders stand for variables in the expression.
+func (e *Expr) HasVariables() bool {
+	return len(e.placeholders) > 0
+}
+
+// String formats the expression and restores the variables replaced by placeholders.
+func (e *Expr) String() string {
+	s := e.AST.Pretty(0)
+	placeholders := make([]string, 0, len(e.placeholders))
+	for p := range e.placeholders {
+		placeholders = append(placeholders, p)
+	}
+	// Restore the longest placeholders first so that none is replaced inside another one.
+	sort.Slice(placeholders, func(i, j int) bool {
+		return len(placeholders[i]) > len(placeholders[j])
+	})
+	for _, p := range placeholders {
+		s = strings.ReplaceAll(s, p, e.placeholders[p])
+	}
+	return s
+}
+
+// stringLiteralEnd returns the index following the string literal starting at start.
+func stringLiteralEnd(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+func precededByOffset(query string) bool {
+	fields := strings.Fields(query)
+	return len(fields) > 0 && strings.EqualFold(fields[len(fields)-1], "offset")
+}
+
+// VariableMatcher is a label matcher of an expression whose value is a variable, such as instance=~"$instance".
+type VariableMatcher struct {
+	Matcher *labels.Matcher
+	// Variable is the name of the variable, without $.
+	Variable string
+}
+
+// VariableMatchers returns the label matchers of the vector selectors of the expression whose whole value is
+// a variable. Variables with a format, such as ${instance:pipe}, are left out since the format decides how
+// their values are matched.
+func (e *Expr) VariableMatchers() []VariableMatcher {
+	var matchers []VariableMatcher
+	parser.Inspect(e.AST, func(node parser.Node, path []parser.Node) error {
+		selector, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		for _, m := range selector.LabelMatchers {
+			groups := variableRegexp.FindStringSubmatch(m.Value)
+			if groups == nil || groups[0] != m.Value || groups[2] != "" {
+				continue
+			}
+			matchers = append(matchers, VariableMatcher{Matcher: m, Variable: groups[1] + groups[3]})
+		}
+		return nil
+	})
+	return matchers
+}
+
+// VariableMatchType returns the match type a matcher on a variable needs: a regex match type when the
+// variable has several values, since Perses joins them into a regex, and an equality one otherwise.
+func VariableMatchType(matchType labels.MatchType, multiValue bool) labels.MatchType {
+	switch {
+	case multiValue && matchType == labels.MatchEqual:
+		return labels.MatchRegexp
+	case multiValue && matchType == labels.MatchNotEqual:
+		return labels.MatchNotRegexp
+	case !multiValue && matchType == labels.MatchRegexp:
+		return labels.MatchEqual
+	case !multiValue && matchType == labels.MatchNotRegexp:
+		return labels.MatchNotEqual
+	}
+	return matchType
+}
+
+// SetVariableMatchTypes parses the query and sets the match type of every matcher on a variable to the one
+// VariableMatchType returns, turning instance="$instance" into instance=~"$instance" when the instance
+// variable has several values and back. multiValue tells, for each variable named without $, whether it
+// allows several values or all of them. The matchers on other variables are left untouched.
+func SetVariableMatchTypes(query string, multiValue map[string]bool) (string, error) {
+	expr, err := ParseExpr(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid PromQL expression %q: %w", query, err)
+	}
+	for _, m := range expr.VariableMatchers() {
+		if multi, ok := multiValue[m.Variable]; ok {
+			m.Matcher.Type = VariableMatchType(m.Matcher.Type, multi)
+		}
+	}
+	return expr.String(), nil
+}
+
+// Variables returns the names, without $, of the variables referenced in the text, such as a query or a
+// datasource name, in the order of their first reference.
+func Variables(text string) []string {
+	var names []string
+	for i := strings.IndexByte(text, '$'); i >= 0; i = strings.IndexByte(text, '$') {
+		text = text[i:]
+		groups := variableRegexp.FindStringSubmatch(text)
+		if groups == nil {
+			text = text[1:]
+			continue
+		}
+		if name := groups[1] + groups[3]; !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+		text = text[len(groups[0]):]
+	}
+	return names
+}
@@ -0,0 +1,197 @@
+package promql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+func TestParseExpr(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query string
+		// variable is the variable replaced by a placeholder, if any.
+		variable string
+		// check checks the node of the AST holding the variable.
+		check func(t *testing.T, e *Expr)
+	}{
+		{
+			name:  "label value",
+			query: `up{job="$job"}`,
+			check: func(t *testing.T, e *Expr) {
+				assertMatcherValue(t, e, "job", "$job")
+			},
+		},
+		{
+			name:  "braced label value",
+			query: `up{job="${job}"}`,
+			check: func(t *testing.T, e *Expr) {
+				assertMatcherValue(t, e, "job", "${job}")
+			},
+		},
+		{
+			name:  "formatted label value",
+			query: `up{instance=~"${instance:regex}"}`,
+			check: func(t *testing.T, e *Expr) {
+				assertMatcherValue(t, e, "instance", "${instance:regex}")
+			},
+		},
+		{
+			name:     "range",
+			query:    `rate(node_cpu_seconds_total[$interval])`,
+			variable: "$interval",
+			check: func(t *testing.T, e *Expr) {
+				selector := e.AST.(*parser.Call).Args[0].(*parser.MatrixSelector)
+				assertDurationPlaceholder(t, e, selector.Range, "$interval")
+			},
+		},
+		{
+			name:     "braced range",
+			query:    `increase(node_cpu_seconds_total[${__rate_interval}])`,
+			variable: "${__rate_interval}",
+			check: func(t *testing.T, e *Expr) {
+				selector := e.AST.(*parser.Call).Args[0].(*parser.MatrixSelector)
+				assertDurationPlaceholder(t, e, selector.Range, "${__rate_interval}")
+			},
+		},
+		{
+			name:     "offset",
+			query:    `up offset $offset`,
+			variable: "$offset",
+			check: func(t *testing.T, e *Expr) {
+				assertDurationPlaceholder(t, e, e.AST.(*parser.VectorSelector).OriginalOffset, "$offset")
+			},
+		},
+		{
+			name:     "grouping label",
+			query:    `sum by ($label) (up)`,
+			variable: "$label",
+			check: func(t *testing.T, e *Expr) {
+				grouping := e.AST.(*parser.AggregateExpr).Grouping
+				if len(grouping) != 1 {
+					t.Fatalf("expected one grouping label, got %v", grouping)
+				}
+				if v, ok := e.Variable(grouping[0]); !ok || v != "$label" {
+					t.Errorf("expected %q to stand for $label, got %q", grouping[0], v)
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := ParseExpr(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.check(t, e)
+
+			var variables []string
+			for _, v := range e.placeholders {
+				variables = append(variables, v)
+			}
+			if tc.variable == "" && len(variables) > 0 || tc.variable != "" && (len(variables) != 1 || variables[0] != tc.variable) {
+				t.Errorf("expected the placeholder of %q, got the ones of %v", tc.variable, variables)
+			}
+			if got := e.String(); got != tc.query {
+				t.Errorf("expected %s to round-trip, got %s", tc.query, got)
+			}
+		})
+	}
+
+	if _, err := ParseExpr(`rate(up[$interval)`); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
+
+func assertMatcherValue(t *testing.T, e *Expr, name, value string) {
+	t.Helper()
+	for _, m := range e.AST.(*parser.VectorSelector).LabelMatchers {
+		if m.Name == name {
+			if m.Value != value {
+				t.Errorf("expected the %s matcher to keep %q, got %q", name, value, m.Value)
+			}
+			return
+		}
+	}
+	t.Errorf("no %s matcher", name)
+}
+
+func assertDurationPlaceholder(t *testing.T, e *Expr, d time.Duration, variable string) {
+	t.Helper()
+	if d < placeholderDurationBase {
+		t.Errorf("expected a placeholder duration, got %s", model.Duration(d))
+	}
+	if v, ok := e.Variable(model.Duration(d).String()); !ok || v != variable {
+		t.Errorf("expected %s to stand for %s, got %q", model.Duration(d), variable, v)
+	}
+}
+
+func TestSetVariableMatchTypes(t *testing.T) {
+	multiValue := map[string]bool{"job": true, "instance": false}
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "multi-value variable",
+			query: `up{job="$job"}`,
+			want:  `up{job=~"$job"}`,
+		},
+		{
+			name:  "negative multi-value variable",
+			query: `up{job!="$job"}`,
+			want:  `up{job!~"$job"}`,
+		},
+		{
+			name:  "single-value variable",
+			query: `up{instance="$instance"}`,
+			want:  `up{instance="$instance"}`,
+		},
+		{
+			name:  "regex on a single-value variable",
+			query: `up{instance=~"${instance}"}`,
+			want:  `up{instance="${instance}"}`,
+		},
+		{
+			name:  "negative regex on a single-value variable",
+			query: `up{instance!~"$instance"}`,
+			want:  `up{instance!="$instance"}`,
+		},
+		{
+			name:  "variable with a format",
+			query: `up{job="${job:csv}"}`,
+			want:  `up{job="${job:csv}"}`,
+		},
+		{
+			name:  "variable in a longer value",
+			query: `up{job="$job-exporter"}`,
+			want:  `up{job="$job-exporter"}`,
+		},
+		{
+			name:  "unknown variable",
+			query: `up{cluster="$cluster"}`,
+			want:  `up{cluster="$cluster"}`,
+		},
+		{
+			name:  "every selector",
+			query: `up{job="$job",instance=~"$instance"} / on (job) group_left () node_uname_info{job="$job"}`,
+			want:  `up{instance="$instance",job=~"$job"} / on (job) group_left () node_uname_info{job=~"$job"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SetVariableMatchTypes(tc.query, multiValue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := SetVariableMatchTypes(`up{job="$job"`, multiValue); err == nil {
+		t.Error("expected an error for an invalid query")
+	}
+}
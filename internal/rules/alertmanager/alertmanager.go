@@ -0,0 +1,22 @@
+package alertmanager
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	alerts "github.com/nicolastakashi/community-perses-dashboards/pkg/alerts/alertmanager"
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+// BuildAlertmanagerAlerts builds the alerting rules of the Alertmanager mixin.
+// The selector of the options is injected in every expression and the extra labels are added to every alert.
+func BuildAlertmanagerAlerts(options rules.Options) (rulesSdk.Builder, error) {
+	return rulesSdk.New("alertmanager-alerts",
+		rulesSdk.AddRuleGroup("alertmanager.rules",
+			alerts.AlertmanagerFailedReload(options.Selector...),
+			alerts.AlertmanagerMembersInconsistent(options.Selector...),
+			alerts.AlertmanagerFailedToSendAlerts(options.Selector...),
+			alerts.AlertmanagerClusterFailedToSendAlerts(options.Selector...),
+			alerts.AlertmanagerConfigInconsistent(options.Selector...),
+		),
+		rulesSdk.Labels(options.ExtraLabels),
+	)
+}
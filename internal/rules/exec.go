@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PrometheusOutput = "prometheus"
+	OperatorOutput   = "operator"
+)
+
+// PrometheusRule is the prometheus-operator custom resource wrapping a set of rule groups.
+type PrometheusRule struct {
+	APIVersion string                 `yaml:"apiVersion"`
+	Kind       string                 `yaml:"kind"`
+	Metadata   PrometheusRuleMetadata `yaml:"metadata"`
+	Spec       rulesSdk.RuleFile      `yaml:"spec"`
+}
+
+type PrometheusRuleMetadata struct {
+	Name string `yaml:"name"`
+}
+
+func executeRulesBuilder(builder rulesSdk.Builder, outputFormat string, outputDir string) (string, error) {
+	var err error
+	var output []byte
+	if outputFormat == PrometheusOutput {
+		output, err = marshalYAML(builder.RuleFile)
+	} else if outputFormat == OperatorOutput {
+		output, err = marshalYAML(PrometheusRule{
+			APIVersion: "monitoring.coreos.com/v1",
+			Kind:       "PrometheusRule",
+			Metadata:   PrometheusRuleMetadata{Name: builder.Name},
+			Spec:       builder.RuleFile,
+		})
+	} else {
+		err = fmt.Errorf("--rules-output must be %q or %q", PrometheusOutput, OperatorOutput)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(outputDir, builder.Name+".yaml")
+	return path, os.WriteFile(path, output, os.ModePerm)
+}
+
+// marshalYAML encodes the value with an indentation of two spaces. With the default indentation of four,
+// yaml.v3 gives the multi-line expressions starting with spaces, such as the pretty-printed binary
+// expressions, an indentation indicator that does not match the indentation of the rule mappings in the
+// sequences, and the file no longer parses.
+func marshalYAML(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func NewExec(outputFormat string, outputDir string) Exec {
+	return Exec{
+		outputFormat: outputFormat,
+		outputDir:    outputDir,
+	}
+}
+
+type Exec struct {
+	outputFormat string
+	outputDir    string
+}
+
+// BuildRules writes the result of a rules builder to the output directory and returns the path of the
+// written file. It returns the builder error or the marshal or write error, naming the rule file.
+func (b *Exec) BuildRules(builder rulesSdk.Builder, err error) (string, error) {
+	var path string
+	if err == nil {
+		path, err = executeRulesBuilder(builder, b.outputFormat, b.outputDir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("rules %q: %w", builder.Name, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,60 @@
+package rules
+
+import (
+	"os"
+	"testing"
+
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExecuteRulesBuilder(t *testing.T) {
+	// Pretty-printed binary expressions span several lines and start with spaces, which yaml.v3 writes as
+	// block scalars with an indentation indicator.
+	expr := "  1\n-\n  avg without (cpu) (\n    sum without (mode) (rate(node_cpu_seconds_total{mode=~\"idle|iowait|steal\"}[5m]))\n  )"
+	builder := rulesSdk.Builder{
+		Name: "node-exporter.rules",
+		RuleFile: rulesSdk.RuleFile{Groups: []rulesSdk.RuleGroup{{
+			Name: "node-exporter.rules",
+			Rules: []rulesSdk.Rule{
+				{Record: "instance:node_cpu_utilisation:rate5m", Expr: expr},
+				{Record: "instance:node_num_cpu:sum", Expr: "count without (cpu) (node_cpu_seconds_total)"},
+			},
+		}}},
+	}
+
+	for _, outputFormat := range []string{PrometheusOutput, OperatorOutput} {
+		t.Run(outputFormat, func(t *testing.T) {
+			path, err := executeRulesBuilder(builder, outputFormat, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ruleFile rulesSdk.RuleFile
+			if outputFormat == OperatorOutput {
+				var rule PrometheusRule
+				err = yaml.Unmarshal(data, &rule)
+				ruleFile = rule.Spec
+			} else {
+				err = yaml.Unmarshal(data, &ruleFile)
+			}
+			if err != nil {
+				t.Fatalf("invalid rule file: %v\n%s", err, data)
+			}
+			if len(ruleFile.Groups) != 1 || len(ruleFile.Groups[0].Rules) != 2 {
+				t.Fatalf("expected one group of two rules, got %+v", ruleFile)
+			}
+			if got := ruleFile.Groups[0].Rules[0].Expr; got != expr {
+				t.Errorf("expected the expression to be kept as is, got %q", got)
+			}
+		})
+	}
+
+	if _, err := executeRulesBuilder(builder, "json", t.TempDir()); err == nil {
+		t.Error("expected an error for an unknown output format")
+	}
+}
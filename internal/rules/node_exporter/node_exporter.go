@@ -0,0 +1,28 @@
+package nodeexporter
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+	records "github.com/nicolastakashi/community-perses-dashboards/pkg/rules/node_exporter"
+)
+
+// BuildNodeExporterRules builds the recording rules queried by the Node Exporter dashboards.
+// The selector of the options is injected in every expression. The extra labels are not added to the
+// recorded series, which the dashboards and other rules join with raw series.
+func BuildNodeExporterRules(options rules.Options) (rulesSdk.Builder, error) {
+	return rulesSdk.New("node-exporter-rules",
+		rulesSdk.AddRuleGroup("node-exporter.rules",
+			records.RecordNodeNumCPUSum(options.Selector...),
+			records.RecordNodeCPUUtilisationRate5m(options.Selector...),
+			records.RecordNodeLoad1PerCPURatio(options.Selector...),
+			records.RecordNodeMemoryUtilisationRatio(options.Selector...),
+			records.RecordNodeVmstatPgmajfaultRate5m(options.Selector...),
+			records.RecordNodeDiskIOTimeSecondsRate5m(options.Selector...),
+			records.RecordNodeDiskIOTimeWeightedSecondsRate5m(options.Selector...),
+			records.RecordNodeNetworkReceiveBytesExcludingLoRate5m(options.Selector...),
+			records.RecordNodeNetworkTransmitBytesExcludingLoRate5m(options.Selector...),
+			records.RecordNodeNetworkReceiveDropExcludingLoRate5m(options.Selector...),
+			records.RecordNodeNetworkTransmitDropExcludingLoRate5m(options.Selector...),
+		),
+	)
+}
@@ -0,0 +1,28 @@
+package nodeexporter
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	alerts "github.com/nicolastakashi/community-perses-dashboards/pkg/alerts/node_exporter"
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+// BuildNodeExporterAlerts builds the alerting rules of the Node Exporter mixin.
+// The selector of the options is injected in every expression and the extra labels are added to every alert.
+func BuildNodeExporterAlerts(options rules.Options) (rulesSdk.Builder, error) {
+	return rulesSdk.New("node-exporter-alerts",
+		rulesSdk.AddRuleGroup("node-exporter",
+			alerts.NodeFilesystemSpaceFillingUp(options.Selector...),
+			alerts.NodeFilesystemAlmostOutOfSpace(options.Selector...),
+			alerts.NodeFilesystemAlmostOutOfFiles(options.Selector...),
+			alerts.NodeNetworkReceiveErrs(options.Selector...),
+			alerts.NodeNetworkTransmitErrs(options.Selector...),
+			alerts.NodeHighNumberConntrackEntriesUsed(options.Selector...),
+			alerts.NodeClockNotSynchronising(options.Selector...),
+			alerts.NodeCPUHighUsage(options.Selector...),
+			alerts.NodeSystemSaturation(options.Selector...),
+			alerts.NodeMemoryHighUtilization(options.Selector...),
+			alerts.NodeDiskIOSaturation(options.Selector...),
+		),
+		rulesSdk.Labels(options.ExtraLabels),
+	)
+}
@@ -0,0 +1,16 @@
+package rules
+
+import "github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+
+// Options holds the settings shared by every rule builder.
+type Options struct {
+	// Selector holds the label matchers selecting the series of the mixin, such as job="node". They are
+	// injected in every expression, replacing the matchers the expressions already have on the same labels.
+	Selector []promql.LabelMatcher
+	// ExtraLabels are added to every alerting rule, for instance to route the alerts of an environment.
+	// The labels a rule sets itself, such as severity, are kept. The recording rules do not get them.
+	ExtraLabels map[string]string
+	// Version is the version of the exporter the rules target, such as 2.53 for Prometheus, so that the
+	// expressions only select the names the renamed metrics have in that version. Empty selects both names.
+	Version string
+}
@@ -0,0 +1,33 @@
+package prometheus
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	alerts "github.com/nicolastakashi/community-perses-dashboards/pkg/alerts/prometheus"
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+// BuildPrometheusAlerts builds the alerting rules of the Prometheus mixin.
+// The selector of the options is injected in every expression and the extra labels are added to every alert.
+// The expressions only select the metric names of the Prometheus version of the options, when set.
+func BuildPrometheusAlerts(options rules.Options) (rulesSdk.Builder, error) {
+	return rulesSdk.New("prometheus-alerts",
+		rulesSdk.AddRuleGroup("prometheus",
+			alerts.PrometheusBadConfig(options.Selector...),
+			alerts.PrometheusNotificationQueueRunningFull(options.Selector...),
+			alerts.PrometheusErrorSendingAlertsToSomeAlertmanagers(options.Selector...),
+			alerts.PrometheusNotConnectedToAlertmanagers(options.Selector...),
+			alerts.PrometheusTSDBReloadsFailing(options.Selector...),
+			alerts.PrometheusTSDBCompactionsFailing(options.Selector...),
+			alerts.PrometheusDuplicateTimestamps(options.Selector...),
+			alerts.PrometheusOutOfOrderTimestamps(options.Selector...),
+			alerts.PrometheusRemoteStorageFailures(options.Selector...),
+			alerts.PrometheusRemoteWriteBehind(options.Selector...),
+			alerts.PrometheusRemoteWriteDesiredShards(options.Selector...),
+			alerts.PrometheusRuleFailures(options.Selector...),
+			alerts.PrometheusMissingRuleEvaluations(options.Selector...),
+			alerts.PrometheusTargetSyncFailure(options.Selector...),
+		),
+		alerts.TargetVersion(options.Version),
+		rulesSdk.Labels(options.ExtraLabels),
+	)
+}
@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+type RuleWriter struct {
+	ruleResults []RuleResult
+	executor    Exec
+	resources   []string
+}
+
+type RuleResult struct {
+	builder rulesSdk.Builder
+	err     error
+}
+
+func NewRuleWriter(outputFormat string, outputDir string) *RuleWriter {
+	return &RuleWriter{
+		executor: NewExec(outputFormat, outputDir),
+	}
+}
+
+// SetOutputDir overrides the output directory of the writer, such as the one of a mixin.
+func (w *RuleWriter) SetOutputDir(outputDir string) {
+	w.executor.outputDir = outputDir
+}
+
+func (w *RuleWriter) Add(builder rulesSdk.Builder, err error) {
+	w.ruleResults = append(w.ruleResults, RuleResult{
+		builder: builder,
+		err:     err,
+	})
+}
+
+// Write writes every result, carrying on after a failure, and returns the errors of all the failed ones.
+func (w *RuleWriter) Write() error {
+	var errs []error
+	for _, result := range w.ruleResults {
+		path, err := w.executor.BuildRules(result.builder, result.err)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if w.executor.outputFormat == OperatorOutput {
+			w.resources = append(w.resources, path)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RuleFiles returns the rule files built without error, along with the errors of all the failed builders.
+func (w *RuleWriter) RuleFiles() ([]rulesSdk.RuleFile, error) {
+	var ruleFiles []rulesSdk.RuleFile
+	var errs []error
+	for _, result := range w.ruleResults {
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("rules %q: %w", result.builder.Name, result.err))
+			continue
+		}
+		ruleFiles = append(ruleFiles, result.builder.RuleFile)
+	}
+	return ruleFiles, errors.Join(errs...)
+}
+
+// RecordedMetrics returns the names of the metrics recorded by the rules built without error.
+func (w *RuleWriter) RecordedMetrics() []string {
+	var metrics []string
+	ruleFiles, _ := w.RuleFiles()
+	for _, ruleFile := range ruleFiles {
+		for _, group := range ruleFile.Groups {
+			for _, rule := range group.Rules {
+				if rule.Record != "" {
+					metrics = append(metrics, rule.Record)
+				}
+			}
+		}
+	}
+	return metrics
+}
+
+// Resources returns the paths of the PrometheusRule custom resources written so far.
+func (w *RuleWriter) Resources() []string {
+	return w.resources
+}
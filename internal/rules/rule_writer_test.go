@@ -0,0 +1,39 @@
+package rules
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+func TestRuleWriterErrors(t *testing.T) {
+	// A directory cannot be created under a file, whatever the permissions of the user running the tests.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writer := NewRuleWriter(PrometheusOutput, filepath.Join(file, "rules"))
+
+	names := []string{"node-exporter.rules", "prometheus-alerts", "alertmanager-alerts"}
+	for _, name := range names {
+		writer.Add(rulesSdk.New(name))
+	}
+	writer.Add(rulesSdk.Builder{Name: "node-exporter-alerts"}, errors.New("invalid expression"))
+
+	err := writer.Write()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if errs := err.(interface{ Unwrap() []error }).Unwrap(); len(errs) != len(names)+1 {
+		t.Errorf("expected an error per rule file, got %d: %v", len(errs), err)
+	}
+	for _, want := range append(names, "node-exporter-alerts", "invalid expression") {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in the error, got %q", want, err)
+		}
+	}
+}
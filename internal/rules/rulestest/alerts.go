@@ -0,0 +1,76 @@
+// Package rulestest provides the test helpers of the alert builders.
+package rulestest
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	rulesSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Selector is the label matcher the alert builders are given, which every vector selector of their
+// expressions must hold.
+var Selector = promql.LabelMatcher{Name: "job", Type: "=", Value: "rulestest"}
+
+// Alert is an alerting rule an alert builder is expected to add.
+type Alert struct {
+	Name     string
+	For      string
+	Severity string
+}
+
+// AssertAlerts checks that the option adds the alerts, in order, with their for duration, their severity and
+// a summary and a description, and that their expressions parse and select the series of Selector only.
+func AssertAlerts(t *testing.T, option rulesSdk.GroupOption, want []Alert) {
+	t.Helper()
+	builder, err := rulesSdk.New("test", rulesSdk.AddRuleGroup("test", option))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := builder.RuleFile.Groups[0].Rules
+	if len(got) != len(want) {
+		t.Fatalf("expected %d alerts, got %d", len(want), len(got))
+	}
+	for i, rule := range got {
+		if rule.Alert != want[i].Name {
+			t.Errorf("expected the alert %s, got %q", want[i].Name, rule.Alert)
+		}
+		if rule.For != want[i].For {
+			t.Errorf("%s: expected to fire after %s, got %q", rule.Alert, want[i].For, rule.For)
+		}
+		if severity := rule.Labels["severity"]; severity != want[i].Severity {
+			t.Errorf("%s: expected the severity %s, got %q", rule.Alert, want[i].Severity, severity)
+		}
+		for _, annotation := range []string{"summary", "description"} {
+			if rule.Annotations[annotation] == "" {
+				t.Errorf("%s: no %s annotation", rule.Alert, annotation)
+			}
+		}
+
+		expr, err := parser.ParseExpr(rule.Expr)
+		if err != nil {
+			t.Errorf("%s: invalid expression %q: %v", rule.Alert, rule.Expr, err)
+			continue
+		}
+		selectors := 0
+		parser.Inspect(expr, func(node parser.Node, path []parser.Node) error {
+			selector, ok := node.(*parser.VectorSelector)
+			if !ok {
+				return nil
+			}
+			selectors++
+			for _, m := range selector.LabelMatchers {
+				if m.Name == Selector.Name && m.Type == labels.MatchEqual && m.Value == Selector.Value {
+					return nil
+				}
+			}
+			t.Errorf("%s: expected %s to hold the selector in %q", rule.Alert, selector, rule.Expr)
+			return nil
+		})
+		if selectors == 0 {
+			t.Errorf("%s: expected a metric selector in %q", rule.Alert, rule.Expr)
+		}
+	}
+}
@@ -1,34 +1,386 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/nicolastakashi/community-perses-dashboards/internal/config"
 	dashboards "github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
-	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/alertmanager"
-	nodeexporter "github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/node_exporter"
-	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/prometheus"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/kustomize"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/lint"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/perses"
+	rules "github.com/nicolastakashi/community-perses-dashboards/internal/rules"
+	alertmanagerrules "github.com/nicolastakashi/community-perses-dashboards/internal/rules/alertmanager"
+	nodeexporterrules "github.com/nicolastakashi/community-perses-dashboards/internal/rules/node_exporter"
+	prometheusrules "github.com/nicolastakashi/community-perses-dashboards/internal/rules/prometheus"
+	dashboardsSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
 )
 
+const (
+	buildCommand = "build"
+	applyCommand = "apply"
+	diffCommand  = "diff"
+	lintCommand  = "lint"
+	listCommand  = "list"
+)
+
+// mixinExporters maps the mixins to the exporter whose metric catalog their queries are linted against.
+var mixinExporters = map[string]string{
+	config.PrometheusMixin:   "prometheus",
+	config.NodeExporterMixin: "node_exporter",
+	config.AlertmanagerMixin: "alertmanager",
+}
+
 var (
+	configFile       string
 	project          string
 	datasource       string
+	datasources      string
 	clusterLabelName string
+	scopeLabels      string
+	rateInterval     string
+
+	nodeExporterSelector string
+	prometheusSelector   string
+	alertmanagerSelector string
+
+	prometheusVersion string
+
+	include            dashboards.Patterns
+	exclude            dashboards.Patterns
+	dashboardOutput    = dashboards.Output{Metadata: dashboards.PersesDashboardMetadata{Labels: map[string]string{}, Annotations: map[string]string{}}}
+	rulesOutput        string
+	rulesOutputDir     string
+	kustomization      bool
+	persesClientConfig perses.ClientConfig
 )
 
 func main() {
 
+	flag.StringVar(&configFile, "config", "", "The YAML or JSON configuration file listing the mixins to render, replacing the flags below")
 	flag.StringVar(&project, "project", "default", "The project name")
 	flag.StringVar(&datasource, "datasource", "", "The datasource name")
+	flag.StringVar(&datasources, "datasources", "", "Comma separated datasource names the dashboards can switch between with a datasource variable, -datasource being the default one")
 	flag.StringVar(&clusterLabelName, "cluster-label-name", "", "The cluster label name")
-	flag.Parse()
+	flag.StringVar(&scopeLabels, "scope-labels", "", "Comma separated labels scoping the series further, such as region,namespace, each filtered by a dashboard variable")
+	flag.StringVar(&rateInterval, "rate-interval", helpers.DefaultRateInterval, "The default range of the rates in the dashboards, e.g. 1m")
+	flag.StringVar(&nodeExporterSelector, "node-exporter-selector", config.DefaultSelectors[config.NodeExporterMixin], "The selector of the Node Exporter series, e.g. job=~\"node.*\"")
+	flag.StringVar(&prometheusSelector, "prometheus-selector", "", "The selector of the Prometheus series")
+	flag.StringVar(&alertmanagerSelector, "alertmanager-selector", "", "The selector of the Alertmanager series")
+	flag.StringVar(&prometheusVersion, "prometheus-version", "", "The Prometheus version the dashboards and rules target, e.g. 2.53, so that the queries only select the names of the renamed metrics in that version")
+	flag.Var(&include, "include", "comma separated patterns of the names of the dashboards to render, such as node-exporter-*, can be repeated")
+	flag.Var(&exclude, "exclude", "comma separated patterns of the names of the dashboards to leave out, can be repeated")
+	flag.StringVar(&dashboardOutput.Format, "output", dashboards.YAMLOutput, "output format of the exec, either json, yaml, operator for PersesDashboard custom resources or grafana for Grafana dashboards")
+	flag.StringVar(&dashboardOutput.Dir, "output-dir", "./dist", "output directory of the exec")
+	flag.StringVar(&dashboardOutput.Metadata.Namespace, "operator-namespace", "", "namespace of the PersesDashboard custom resources")
+	flag.Var(dashboards.KeyValueFlag(dashboardOutput.Metadata.Labels), "operator-labels", "comma separated key=value labels of the PersesDashboard custom resources, can be repeated")
+	flag.Var(dashboards.KeyValueFlag(dashboardOutput.Metadata.Annotations), "operator-annotations", "comma separated key=value annotations of the PersesDashboard custom resources, can be repeated")
+	flag.StringVar(&rulesOutput, "rules-output", rules.PrometheusOutput, "output format of the rules, either a Prometheus rule file or a PrometheusRule custom resource")
+	flag.StringVar(&rulesOutputDir, "rules-output-dir", "./dist/rules", "output directory of the rules")
+	flag.BoolVar(&kustomization, "kustomization", false, "write a kustomization.yaml listing the custom resources generated in each output directory, requires --output=operator or --rules-output=operator")
+	flag.StringVar(&persesClientConfig.URL, "perses-url", "", "URL of the Perses API server the dashboards are applied to")
+	flag.StringVar(&persesClientConfig.Token, "perses-token", "", "bearer token of the Perses API, defaults to the "+perses.TokenEnv+" environment variable")
+	flag.StringVar(&persesClientConfig.Username, "perses-username", "", "username of the Perses API basic authentication")
+	flag.StringVar(&persesClientConfig.Password, "perses-password", "", "password of the Perses API basic authentication, defaults to the "+perses.PasswordEnv+" environment variable")
+	flag.StringVar(&persesClientConfig.CAFile, "perses-ca-file", "", "CA certificate file used to verify the Perses API server")
+	flag.StringVar(&persesClientConfig.CertFile, "perses-cert-file", "", "client certificate file of the Perses API")
+	flag.StringVar(&persesClientConfig.KeyFile, "perses-key-file", "", "client key file of the Perses API")
+	flag.BoolVar(&persesClientConfig.InsecureSkipVerify, "perses-insecure-skip-verify", false, "skip the verification of the Perses API server certificate")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [%s|%s|%s|%s|%s] [flags]\n\n", os.Args[0], buildCommand, applyCommand, diffCommand, lintCommand, listCommand)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\twrite the dashboards and rules to the output directories (default)\n", buildCommand)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\tcreate or update the dashboards in the Perses API server given by -perses-url\n", applyCommand)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\tcompare the dashboards with the output directories, or the Perses API when -perses-url is set\n", diffCommand)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\tcheck the metrics of the dashboard queries against the metric catalog of their exporter\n", lintCommand)
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\tprint the dashboards selected by -include and -exclude\n\nFlags:\n", listCommand)
+		flag.PrintDefaults()
+	}
+
+	command, args := buildCommand, os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	_ = flag.CommandLine.Parse(args)
+
+	commands := map[string]func(config.Config) error{
+		buildCommand: build,
+		applyCommand: apply,
+		diffCommand:  diff,
+		lintCommand:  lintDashboards,
+		listCommand:  list,
+	}
+	run, ok := commands[command]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := run(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// build writes the dashboards and the rules of every mixin, then the kustomization when nothing failed.
+func build(cfg config.Config) error {
+	kustomizationWriter := kustomize.NewKustomizationWriter(kustomization)
+
+	var errs []error
+	for _, mixin := range cfg.Mixins {
+		if err := renderMixin(mixin, kustomizationWriter); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, kustomizationWriter.Write())
+	}
+	return errors.Join(errs...)
+}
+
+// apply creates or updates the dashboards of every mixin in the Perses API. The rules are not applied,
+// since Perses does not evaluate them.
+func apply(cfg config.Config) error {
+	client, err := perses.NewClient(persesClientConfig.WithEnv(), nil)
+	if err != nil {
+		return err
+	}
 
-	dashboardWriter := dashboards.NewDashboardWriter()
+	var errs []error
+	for _, mixin := range cfg.Mixins {
+		dashboardWriter, err := newDashboardWriter(mixin, client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		errs = append(errs, dashboardWriter.Write())
+	}
+	return errors.Join(errs...)
+}
+
+// diff compares the dashboards of every mixin with the deployed ones, in the Perses API when -perses-url is
+// set and in the output directories otherwise, prints the changes and fails when any dashboard drifted.
+func diff(cfg config.Config) error {
+	var client *perses.Client
+	if clientConfig := persesClientConfig.WithEnv(); clientConfig.URL != "" {
+		var err error
+		if client, err = perses.NewClient(clientConfig, nil); err != nil {
+			return err
+		}
+	}
 
-	dashboardWriter.Add(prometheus.BuildPrometheusOverview(project, datasource, clusterLabelName))
-	dashboardWriter.Add(prometheus.BuildPrometheusRemoteWrite(project, datasource, clusterLabelName))
-	dashboardWriter.Add(nodeexporter.BuildNodeExporterNodes(project, datasource, clusterLabelName))
-	dashboardWriter.Add(nodeexporter.BuildNodeExporterClusterUseMethod(project, datasource, clusterLabelName))
-	dashboardWriter.Add(alertmanager.BuildAlertManagerOverview(project, datasource, clusterLabelName))
+	var errs []error
+	drifted := 0
+	for _, mixin := range cfg.Mixins {
+		dashboardWriter, err := newDashboardWriter(mixin, client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		n, err := dashboardWriter.Diff(os.Stdout)
+		drifted += n
+		errs = append(errs, err)
+	}
+	if drifted > 0 {
+		errs = append(errs, fmt.Errorf("drift detected in %d dashboard(s)", drifted))
+	}
+	return errors.Join(errs...)
+}
+
+// list prints the name, mixin, tags and description of the dashboards of every mixin selected by the filter.
+func list(cfg config.Config) error {
+	filter := dashboards.Filter{Include: include, Exclude: exclude}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tMIXIN\tTAGS\tDESCRIPTION")
+	listed := map[string]bool{}
+	for _, mixin := range cfg.Mixins {
+		if listed[mixin.Type] {
+			continue
+		}
+		listed[mixin.Type] = true
+		for _, registration := range dashboards.Registrations(mixin.Type, filter) {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", registration.Name, registration.Mixin, strings.Join(registration.Tags, ","), registration.Description)
+		}
+	}
+	return writer.Flush()
+}
+
+// lintDashboards checks the queries of the dashboards of every mixin against the metric catalog of its exporter
+// and prints the issues found. Warnings, such as metrics the latest exporter version no longer exposes, do not
+// fail.
+func lintDashboards(cfg config.Config) error {
+	var errs []error
+	errorCount := 0
+	for _, mixin := range cfg.Mixins {
+		n, err := lintMixin(mixin, os.Stdout)
+		errorCount += n
+		errs = append(errs, err)
+	}
+	if errorCount > 0 {
+		errs = append(errs, fmt.Errorf("%d lint error(s) found", errorCount))
+	}
+	return errors.Join(errs...)
+}
+
+// lintMixin prints the issues of the dashboards of the mixin, the metrics recorded by its rules being known,
+// and returns the number of errors.
+func lintMixin(mixin config.Mixin, out io.Writer) (int, error) {
+	dashboardOptions, err := mixin.DashboardOptions()
+	if err != nil {
+		return 0, fmt.Errorf("mixin %q: %w", mixin.Type, err)
+	}
+	ruleOptions, err := mixin.RuleOptions()
+	if err != nil {
+		return 0, fmt.Errorf("mixin %q: %w", mixin.Type, err)
+	}
+	catalog, err := lint.LoadCatalog(mixinExporters[mixin.Type])
+	if err != nil {
+		return 0, fmt.Errorf("mixin %q: %w", mixin.Type, err)
+	}
+
+	ruleWriter := rules.NewRuleWriter(rulesOutput, rulesOutputDir)
+	addRules(ruleWriter, mixin.Type, ruleOptions)
+	linter := lint.NewLinter(catalog, ruleWriter.RecordedMetrics())
+
+	dashboardWriter := dashboards.NewDashboardWriter(dashboardOutput)
+	addDashboards(dashboardWriter, mixin.Type, dashboardOptions)
+	builtDashboards, err := dashboardWriter.Dashboards()
+	errs := []error{err}
+
+	errorCount := 0
+	for _, dashboard := range builtDashboards {
+		issues, err := linter.Lint(dashboard)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("dashboard %q: %w", dashboard.Metadata.Name, err))
+			continue
+		}
+		for _, issue := range issues {
+			fmt.Fprintln(out, issue)
+			if issue.Severity == lint.Error {
+				errorCount++
+			}
+		}
+	}
+	return errorCount, errors.Join(errs...)
+}
+
+// newDashboardWriter returns a writer holding the dashboards of the mixin, using the client when not nil.
+func newDashboardWriter(mixin config.Mixin, client *perses.Client) (*dashboards.DashboardWriter, error) {
+	dashboardOptions, err := mixin.DashboardOptions()
+	if err != nil {
+		return nil, fmt.Errorf("mixin %q: %w", mixin.Type, err)
+	}
+	dashboardWriter := dashboards.NewDashboardWriter(dashboardOutput)
+	if mixin.OutputDir != "" {
+		dashboardWriter.SetOutputDir(mixin.OutputDir)
+	}
+	if client != nil {
+		dashboardWriter.SetClient(client)
+	}
+	addDashboards(dashboardWriter, mixin.Type, dashboardOptions)
+	return dashboardWriter, nil
+}
+
+// loadConfig reads the configuration file, or builds the configuration from the flags when no file is given.
+func loadConfig() (config.Config, error) {
+	if configFile != "" {
+		return config.Load(configFile)
+	}
+
+	selectors := map[string]string{
+		config.PrometheusMixin:   prometheusSelector,
+		config.NodeExporterMixin: nodeExporterSelector,
+		config.AlertmanagerMixin: alertmanagerSelector,
+	}
+	versions := map[string]string{
+		config.PrometheusMixin: prometheusVersion,
+	}
+	var datasourceNames []string
+	if datasources != "" {
+		datasourceNames = strings.Split(datasources, ",")
+	}
+	var scopeLabelNames []string
+	if scopeLabels != "" {
+		scopeLabelNames = strings.Split(scopeLabels, ",")
+	}
+	var cfg config.Config
+	for _, mixinType := range config.MixinTypes {
+		selector := selectors[mixinType]
+		cfg.Mixins = append(cfg.Mixins, config.Mixin{
+			Type:             mixinType,
+			Project:          project,
+			Datasource:       datasource,
+			Datasources:      datasourceNames,
+			ClusterLabelName: clusterLabelName,
+			ScopeLabels:      scopeLabelNames,
+			Selector:         &selector,
+			Version:          versions[mixinType],
+			RateInterval:     rateInterval,
+		})
+	}
+	if err := cfg.Validate(); err != nil {
+		return config.Config{}, fmt.Errorf("invalid flags: %w", err)
+	}
+	return cfg, nil
+}
+
+// renderMixin writes the dashboards and the rules of the mixin and lists the written custom resources in
+// the kustomization. It returns the errors of every dashboard and rule file that could not be written.
+func renderMixin(mixin config.Mixin, kustomizationWriter *kustomize.KustomizationWriter) error {
+	dashboardOptions, err := mixin.DashboardOptions()
+	if err != nil {
+		return fmt.Errorf("mixin %q: %w", mixin.Type, err)
+	}
+	ruleOptions, err := mixin.RuleOptions()
+	if err != nil {
+		return fmt.Errorf("mixin %q: %w", mixin.Type, err)
+	}
+
+	dashboardWriter := dashboards.NewDashboardWriter(dashboardOutput)
+	if mixin.OutputDir != "" {
+		dashboardWriter.SetOutputDir(mixin.OutputDir)
+	}
+	ruleWriter := rules.NewRuleWriter(rulesOutput, rulesOutputDir)
+	if rulesDir := mixin.RulesDir(""); rulesDir != "" {
+		ruleWriter.SetOutputDir(rulesDir)
+	}
+
+	addDashboards(dashboardWriter, mixin.Type, dashboardOptions)
+	addRules(ruleWriter, mixin.Type, ruleOptions)
+
+	err = errors.Join(dashboardWriter.Write(), ruleWriter.Write())
+	kustomizationWriter.Add(dashboardWriter.OutputDir(), append(dashboardWriter.Resources(), ruleWriter.Resources()...)...)
+	return err
+}
+
+// addDashboards adds the registered dashboards of the mixin selected by the -include and -exclude flags to
+// the writer.
+func addDashboards(dashboardWriter *dashboards.DashboardWriter, mixinType string, options dashboardsSdk.Options) {
+	for _, registration := range dashboards.Registrations(mixinType, dashboards.Filter{Include: include, Exclude: exclude}) {
+		dashboardWriter.Add(registration.Build(dashboardsSdk.WithOptions(options)))
+	}
+}
 
-	dashboardWriter.Write()
+// addRules adds the rule files of the mixin to the writer.
+func addRules(ruleWriter *rules.RuleWriter, mixinType string, options rules.Options) {
+	switch mixinType {
+	case config.PrometheusMixin:
+		ruleWriter.Add(prometheusrules.BuildPrometheusAlerts(options))
+	case config.NodeExporterMixin:
+		ruleWriter.Add(nodeexporterrules.BuildNodeExporterRules(options))
+		ruleWriter.Add(nodeexporterrules.BuildNodeExporterAlerts(options))
+	case config.AlertmanagerMixin:
+		ruleWriter.Add(alertmanagerrules.BuildAlertmanagerAlerts(options))
+	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mainArgsEnv holds the arguments of the command run by TestMainProcess, separated by argsSeparator.
+const (
+	mainArgsEnv   = "MAIN_TEST_ARGS"
+	argsSeparator = "\x1f"
+)
+
+// TestMainProcess runs the command in the process started by runMain, since main registers its flags and
+// exits.
+func TestMainProcess(t *testing.T) {
+	args, ok := os.LookupEnv(mainArgsEnv)
+	if !ok {
+		t.Skip("only run by runMain")
+	}
+	os.Args = append([]string{os.Args[0]}, strings.Split(args, argsSeparator)...)
+	main()
+	os.Exit(0)
+}
+
+// runMain runs the command with the arguments in a new process and returns its exit code and output.
+func runMain(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMainProcess$")
+	cmd.Env = append(os.Environ(), mainArgsEnv+"="+strings.Join(args, argsSeparator))
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), output.String()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0, output.String()
+}
+
+func TestDiffCommand(t *testing.T) {
+	dir := t.TempDir()
+	flags := []string{"-output-dir", dir, "-rules-output-dir", filepath.Join(dir, "rules"), "-include", "node-exporter-nodes"}
+
+	if code, output := runMain(t, append([]string{"build"}, flags...)...); code != 0 {
+		t.Fatalf("build exited with %d: %s", code, output)
+	}
+	if code, output := runMain(t, append([]string{"diff"}, flags...)...); code != 0 {
+		t.Fatalf("expected no drift right after the build, exited with %d: %s", code, output)
+	}
+
+	path := filepath.Join(dir, "node-exporter-nodes.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drifted := bytes.Replace(data, []byte("node_load1"), []byte("node_load5"), 1)
+	if bytes.Equal(drifted, data) {
+		t.Fatal("expected node_load1 in the node dashboard")
+	}
+	if err := os.WriteFile(path, drifted, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	code, output := runMain(t, append([]string{"diff"}, flags...)...)
+	if code != 1 {
+		t.Fatalf("expected the diff to exit with 1 on drift, got %d: %s", code, output)
+	}
+	for _, want := range []string{`dashboard "node-exporter-nodes"`, "node_load5", "drift detected in 1 dashboard(s)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in the output:\n%s", want, output)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package alertmanager
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+// AlertmanagerFailedReload creates an alerting rule that fires when Alertmanager failed to reload its configuration.
+//
+// The alert uses the following Prometheus metrics:
+// - alertmanager_config_last_reload_successful: Whether the last configuration reload attempt was successful
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func AlertmanagerFailedReload(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "AlertmanagerFailedReload",
+		For:   "10m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Reloading an Alertmanager configuration has failed.",
+			"description": "Configuration has failed to load for {{ $labels.instance }}.",
+		},
+	}, promql.Metric("alertmanager_config_last_reload_successful").MaxOverTime("5m").Equal(promql.Number(0)), labelMatchers)
+}
+
+// AlertmanagerMembersInconsistent creates an alerting rule that fires when an Alertmanager instance has
+// not found all the other members of its cluster.
+//
+// The alert uses the following Prometheus metrics:
+// - alertmanager_cluster_members: Number of members in the Alertmanager cluster
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func AlertmanagerMembersInconsistent(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "AlertmanagerMembersInconsistent",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "A member of an Alertmanager cluster has not found all other cluster members.",
+			"description": "Alertmanager {{ $labels.instance }} has only found {{ $value }} members of the {{ $labels.job }} cluster.",
+		},
+	}, promql.Metric("alertmanager_cluster_members").MaxOverTime("5m").
+		LessThan(promql.Metric("alertmanager_cluster_members").MaxOverTime("5m").CountBy("job"), promql.On("job"), promql.GroupLeft()), labelMatchers)
+}
+
+// AlertmanagerFailedToSendAlerts creates an alerting rule that fires when an Alertmanager instance fails
+// to send more than 1% of its notifications to an integration.
+//
+// The alert uses the following Prometheus metrics:
+// - alertmanager_notifications_failed_total: Total count of failed notification attempts
+// - alertmanager_notifications_total: Total count of notifications sent
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func AlertmanagerFailedToSendAlerts(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "AlertmanagerFailedToSendAlerts",
+		For:   "5m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "An Alertmanager instance failed to send notifications.",
+			"description": "Alertmanager {{ $labels.instance }} failed to send {{ $value | humanizePercentage }} of notifications to {{ $labels.integration }}.",
+		},
+	}, notificationsFailedRatio().GreaterThan(promql.Number(0.01)), labelMatchers)
+}
+
+// AlertmanagerClusterFailedToSendAlerts creates an alerting rule that fires when every instance of an
+// Alertmanager cluster fails to send more than 1% of its notifications to an integration.
+//
+// The alert uses the following Prometheus metrics:
+// - alertmanager_notifications_failed_total: Total count of failed notification attempts
+// - alertmanager_notifications_total: Total count of notifications sent
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func AlertmanagerClusterFailedToSendAlerts(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "AlertmanagerClusterFailedToSendAlerts",
+		For:   "5m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "All Alertmanager instances in a cluster failed to send notifications.",
+			"description": "The minimum notification failure rate to {{ $labels.integration }} sent from any instance in the {{ $labels.job }} cluster is {{ $value | humanizePercentage }}.",
+		},
+	}, notificationsFailedRatio().MinBy("job", "integration").GreaterThan(promql.Number(0.01)), labelMatchers)
+}
+
+// AlertmanagerConfigInconsistent creates an alerting rule that fires when the instances of an
+// Alertmanager cluster run with different configurations.
+//
+// The alert uses the following Prometheus metrics:
+// - alertmanager_config_hash: Hash of the currently loaded Alertmanager configuration
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func AlertmanagerConfigInconsistent(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRule(rules.Rule{
+		Alert: "AlertmanagerConfigInconsistent",
+		Expr:  "count by (job) (count_values by (job) ('config_hash', alertmanager_config_hash)) != 1",
+		For:   "20m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Alertmanager instances within the same cluster have different configurations.",
+			"description": "Alertmanager instances within the {{ $labels.job }} cluster have different configurations.",
+		},
+	}, labelMatchers)
+}
+
+// notificationsFailedRatio returns the ratio of the notifications that failed, for each reason of the failures.
+func notificationsFailedRatio() promql.Query {
+	return promql.Metric("alertmanager_notifications_failed_total").Rate("5m").
+		Div(promql.Metric("alertmanager_notifications_total").Rate("5m"), promql.Ignoring("reason"), promql.GroupLeft())
+}
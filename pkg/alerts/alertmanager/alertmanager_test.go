@@ -0,0 +1,25 @@
+package alertmanager
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules/rulestest"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+func TestAlerts(t *testing.T) {
+	for _, tc := range []struct {
+		option rules.GroupOption
+		want   []rulestest.Alert
+	}{
+		{AlertmanagerFailedReload(rulestest.Selector), []rulestest.Alert{{Name: "AlertmanagerFailedReload", For: "10m", Severity: "critical"}}},
+		{AlertmanagerMembersInconsistent(rulestest.Selector), []rulestest.Alert{{Name: "AlertmanagerMembersInconsistent", For: "15m", Severity: "critical"}}},
+		{AlertmanagerFailedToSendAlerts(rulestest.Selector), []rulestest.Alert{{Name: "AlertmanagerFailedToSendAlerts", For: "5m", Severity: "warning"}}},
+		{AlertmanagerClusterFailedToSendAlerts(rulestest.Selector), []rulestest.Alert{{Name: "AlertmanagerClusterFailedToSendAlerts", For: "5m", Severity: "critical"}}},
+		{AlertmanagerConfigInconsistent(rulestest.Selector), []rulestest.Alert{{Name: "AlertmanagerConfigInconsistent", For: "20m", Severity: "critical"}}},
+	} {
+		t.Run(tc.want[0].Name, func(t *testing.T) {
+			rulestest.AssertAlerts(t, tc.option, tc.want)
+		})
+	}
+}
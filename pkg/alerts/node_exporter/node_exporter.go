@@ -0,0 +1,356 @@
+package nodeexporter
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+	nodeexporterrules "github.com/nicolastakashi/community-perses-dashboards/pkg/rules/node_exporter"
+)
+
+// NodeFilesystemSpaceFillingUp creates alerting rules that fire when a filesystem is predicted to run
+// out of space within the next 24 hours (warning) or 4 hours (critical).
+//
+// The alerts use the following Prometheus metrics:
+// - node_filesystem_avail_bytes: Filesystem space available to non-root users
+// - node_filesystem_size_bytes: Filesystem size
+// - node_filesystem_readonly: Whether the filesystem is mounted read-only
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expressions.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeFilesystemSpaceFillingUp(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.Rules(
+		rules.AddAlertingRuleQuery(rules.Rule{
+			Alert: "NodeFilesystemSpaceFillingUp",
+			For:   "1h",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Filesystem is predicted to run out of space within the next 24 hours.",
+				"description": "Filesystem on {{ $labels.device }}, mounted on {{ $labels.mountpoint }}, at {{ $labels.instance }} has only {{ printf \"%.2f\" $value }}% available space left and is filling up.",
+			},
+		}, filesystemSpaceFillingUp(15, 24), labelMatchers),
+		rules.AddAlertingRuleQuery(rules.Rule{
+			Alert: "NodeFilesystemSpaceFillingUp",
+			For:   "1h",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "Filesystem is predicted to run out of space within the next 4 hours.",
+				"description": "Filesystem on {{ $labels.device }}, mounted on {{ $labels.mountpoint }}, at {{ $labels.instance }} has only {{ printf \"%.2f\" $value }}% available space left and is filling up fast.",
+			},
+		}, filesystemSpaceFillingUp(10, 4), labelMatchers),
+	)
+}
+
+// NodeFilesystemAlmostOutOfSpace creates alerting rules that fire when a filesystem has less than 5%
+// (warning) or 3% (critical) of its space left.
+//
+// The alerts use the following Prometheus metrics:
+// - node_filesystem_avail_bytes: Filesystem space available to non-root users
+// - node_filesystem_size_bytes: Filesystem size
+// - node_filesystem_readonly: Whether the filesystem is mounted read-only
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expressions.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeFilesystemAlmostOutOfSpace(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.Rules(
+		rules.AddAlertingRuleQuery(rules.Rule{
+			Alert: "NodeFilesystemAlmostOutOfSpace",
+			For:   "30m",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "Filesystem has less than 5% space left.",
+				"description": "Filesystem on {{ $labels.device }}, mounted on {{ $labels.mountpoint }}, at {{ $labels.instance }} has only {{ printf \"%.2f\" $value }}% available space left.",
+			},
+		}, filesystemAvailableBelow("node_filesystem_avail_bytes", "node_filesystem_size_bytes", 5), labelMatchers),
+		rules.AddAlertingRuleQuery(rules.Rule{
+			Alert: "NodeFilesystemAlmostOutOfSpace",
+			For:   "30m",
+			Labels: map[string]string{
+				"severity": "critical",
+			},
+			Annotations: map[string]string{
+				"summary":     "Filesystem has less than 3% space left.",
+				"description": "Filesystem on {{ $labels.device }}, mounted on {{ $labels.mountpoint }}, at {{ $labels.instance }} has only {{ printf \"%.2f\" $value }}% available space left.",
+			},
+		}, filesystemAvailableBelow("node_filesystem_avail_bytes", "node_filesystem_size_bytes", 3), labelMatchers),
+	)
+}
+
+// NodeFilesystemAlmostOutOfFiles creates an alerting rule that fires when a filesystem has less than 5%
+// of its inodes left.
+//
+// The alert uses the following Prometheus metrics:
+// - node_filesystem_files_free: Free inodes of the filesystem
+// - node_filesystem_files: Total inodes of the filesystem
+// - node_filesystem_readonly: Whether the filesystem is mounted read-only
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeFilesystemAlmostOutOfFiles(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeFilesystemAlmostOutOfFiles",
+		For:   "1h",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Filesystem has less than 5% inodes left.",
+			"description": "Filesystem on {{ $labels.device }}, mounted on {{ $labels.mountpoint }}, at {{ $labels.instance }} has only {{ printf \"%.2f\" $value }}% available inodes left.",
+		},
+	}, filesystemAvailableBelow("node_filesystem_files_free", "node_filesystem_files", 5), labelMatchers)
+}
+
+// NodeNetworkReceiveErrs creates an alerting rule that fires when a network interface reports receive
+// errors for more than 1% of its packets.
+//
+// The alert uses the following Prometheus metrics:
+// - node_network_receive_errs_total: Total receive errors
+// - node_network_receive_packets_total: Total received packets
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeNetworkReceiveErrs(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeNetworkReceiveErrs",
+		For:   "1h",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Network interface is reporting many receive errors.",
+			"description": "{{ $labels.instance }} interface {{ $labels.device }} has encountered {{ printf \"%.0f\" $value }} receive errors in the last two minutes.",
+		},
+	}, promql.Metric("node_network_receive_errs_total").Rate("2m").
+		Div(promql.Metric("node_network_receive_packets_total").Rate("2m")).
+		GreaterThan(promql.Number(0.01)), labelMatchers)
+}
+
+// NodeNetworkTransmitErrs creates an alerting rule that fires when a network interface reports transmit
+// errors for more than 1% of its packets.
+//
+// The alert uses the following Prometheus metrics:
+// - node_network_transmit_errs_total: Total transmit errors
+// - node_network_transmit_packets_total: Total transmitted packets
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeNetworkTransmitErrs(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeNetworkTransmitErrs",
+		For:   "1h",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Network interface is reporting many transmit errors.",
+			"description": "{{ $labels.instance }} interface {{ $labels.device }} has encountered {{ printf \"%.0f\" $value }} transmit errors in the last two minutes.",
+		},
+	}, promql.Metric("node_network_transmit_errs_total").Rate("2m").
+		Div(promql.Metric("node_network_transmit_packets_total").Rate("2m")).
+		GreaterThan(promql.Number(0.01)), labelMatchers)
+}
+
+// NodeHighNumberConntrackEntriesUsed creates an alerting rule that fires when more than 75% of the
+// conntrack entries are used.
+//
+// The alert uses the following Prometheus metrics:
+// - node_nf_conntrack_entries: Number of currently allocated flow entries
+// - node_nf_conntrack_entries_limit: Maximum size of the connection tracking table
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeHighNumberConntrackEntriesUsed(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeHighNumberConntrackEntriesUsed",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Number of conntrack entries is getting close to the limit.",
+			"description": "{{ $value | humanizePercentage }} of conntrack entries are used on {{ $labels.instance }}.",
+		},
+	}, promql.Metric("node_nf_conntrack_entries").
+		Div(promql.Metric("node_nf_conntrack_entries_limit")).
+		GreaterThan(promql.Number(0.75)), labelMatchers)
+}
+
+// NodeClockNotSynchronising creates an alerting rule that fires when the clock of a node is not
+// synchronised by NTP.
+//
+// The alert uses the following Prometheus metrics:
+// - node_timex_sync_status: Whether the clock is synchronised to a reliable server
+// - node_timex_maxerror_seconds: Maximum error of the clock
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeClockNotSynchronising(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeClockNotSynchronising",
+		For:   "10m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Clock is not synchronising.",
+			"description": "Clock at {{ $labels.instance }} is not synchronising. Ensure NTP is configured on this host.",
+		},
+	}, promql.Metric("node_timex_sync_status").MinOverTime("5m").Equal(promql.Number(0)).
+		And(promql.Metric("node_timex_maxerror_seconds").GreaterOrEqual(promql.Number(16))), labelMatchers)
+}
+
+// NodeCPUHighUsage creates an alerting rule that fires when the CPU usage of a node stays above 90%.
+//
+// The alert uses the following Prometheus metrics:
+// - node_cpu_seconds_total: CPU time spent in different modes
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeCPUHighUsage(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeCPUHighUsage",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "info",
+		},
+		Annotations: map[string]string{
+			"summary":     "High CPU usage.",
+			"description": "CPU usage at {{ $labels.instance }} has been above 90% for the last 15 minutes, is currently at {{ printf \"%.2f\" $value }}%.",
+		},
+	}, promql.Metric("node_cpu_seconds_total", promql.LabelMatcher{Name: "mode", Type: "!~", Value: "idle|iowait"}).
+		Rate("2m").
+		AvgWithout("cpu").
+		SumWithout("mode").
+		Mul(promql.Number(100)).
+		GreaterThan(promql.Number(90)), labelMatchers)
+}
+
+// NodeSystemSaturation creates an alerting rule that fires when the 1-minute load average per CPU of
+// a node stays above 2.
+//
+// The alert uses the following Prometheus metrics:
+// - node_load1: 1-minute load average
+// - node_cpu_seconds_total: CPU time spent in different modes, used to count CPUs
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeSystemSaturation(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeSystemSaturation",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "System saturated, load per core is very high.",
+			"description": "System load per core at {{ $labels.instance }} has been above 2 for the last 15 minutes, is currently at {{ printf \"%.2f\" $value }}.",
+		},
+	}, promql.Metric("node_load1").Div(nodeexporterrules.NumCPU()).GreaterThan(promql.Number(2)), labelMatchers)
+}
+
+// NodeMemoryHighUtilization creates an alerting rule that fires when a node uses more than 90% of its memory.
+//
+// The alert uses the following Prometheus metrics:
+// - node_memory_MemAvailable_bytes: Available memory in bytes
+// - node_memory_MemTotal_bytes: Total physical memory in bytes
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeMemoryHighUtilization(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeMemoryHighUtilization",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Host is running out of memory.",
+			"description": "Memory is filling up at {{ $labels.instance }}, has been above 90% for the last 15 minutes, is currently at {{ printf \"%.2f\" $value }}%.",
+		},
+	}, promql.Number(100).
+		Sub(promql.Metric("node_memory_MemAvailable_bytes").Div(promql.Metric("node_memory_MemTotal_bytes")).Mul(promql.Number(100))).
+		GreaterThan(promql.Number(90)), labelMatchers)
+}
+
+// NodeDiskIOSaturation creates an alerting rule that fires when the I/O queue of a disk device stays
+// above 10 operations.
+//
+// The alert uses the following Prometheus metrics:
+// - node_disk_io_time_weighted_seconds_total: Weighted seconds spent doing I/O
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func NodeDiskIOSaturation(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "NodeDiskIOSaturation",
+		For:   "30m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Disk IO queue is high.",
+			"description": "Disk IO queue (aqu-sq) is high on {{ $labels.device }} at {{ $labels.instance }}, has been above 10 for the last 30 minutes, is currently at {{ printf \"%.2f\" $value }}.",
+		},
+	}, promql.Metric("node_disk_io_time_weighted_seconds_total", promql.LabelMatcher{Name: "device", Type: "=~", Value: "(/dev/)?(mmcblk.p.+|nvme.+|rbd.+|sd.+|vd.+|xvd.+|dm-.+|md.+|dasd.+)"}).
+		Rate("5m").
+		GreaterThan(promql.Number(10)), labelMatchers)
+}
+
+// filesystemMatchers select the series of the filesystems having a type and a mount point.
+var filesystemMatchers = []promql.LabelMatcher{
+	{Name: "fstype", Type: "!=", Value: ""},
+	{Name: "mountpoint", Type: "!=", Value: ""},
+}
+
+// filesystemAvailableBelow returns the percentage of the space or inodes left on the writable filesystems, given
+// by the metrics of the available and total amounts, when below the percentage.
+func filesystemAvailableBelow(available string, size string, percentage float64) promql.Query {
+	return promql.Metric(available, filesystemMatchers...).
+		Div(promql.Metric(size, filesystemMatchers...)).
+		Mul(promql.Number(100)).
+		LessThan(promql.Number(percentage)).
+		And(promql.Metric("node_filesystem_readonly", filesystemMatchers...).Equal(promql.Number(0)))
+}
+
+// filesystemSpaceFillingUp returns the percentage of the space left on the writable filesystems below the
+// percentage that are predicted to run out of space within the hours.
+func filesystemSpaceFillingUp(percentage float64, hours float64) promql.Query {
+	return filesystemAvailableBelow("node_filesystem_avail_bytes", "node_filesystem_size_bytes", percentage).
+		And(promql.Metric("node_filesystem_avail_bytes", filesystemMatchers...).PredictLinear("6h", hours*60*60).LessThan(promql.Number(0)))
+}
@@ -0,0 +1,37 @@
+package nodeexporter
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules/rulestest"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+func TestAlerts(t *testing.T) {
+	for _, tc := range []struct {
+		option rules.GroupOption
+		want   []rulestest.Alert
+	}{
+		{NodeFilesystemSpaceFillingUp(rulestest.Selector), []rulestest.Alert{
+			{Name: "NodeFilesystemSpaceFillingUp", For: "1h", Severity: "warning"},
+			{Name: "NodeFilesystemSpaceFillingUp", For: "1h", Severity: "critical"},
+		}},
+		{NodeFilesystemAlmostOutOfSpace(rulestest.Selector), []rulestest.Alert{
+			{Name: "NodeFilesystemAlmostOutOfSpace", For: "30m", Severity: "warning"},
+			{Name: "NodeFilesystemAlmostOutOfSpace", For: "30m", Severity: "critical"},
+		}},
+		{NodeFilesystemAlmostOutOfFiles(rulestest.Selector), []rulestest.Alert{{Name: "NodeFilesystemAlmostOutOfFiles", For: "1h", Severity: "warning"}}},
+		{NodeNetworkReceiveErrs(rulestest.Selector), []rulestest.Alert{{Name: "NodeNetworkReceiveErrs", For: "1h", Severity: "warning"}}},
+		{NodeNetworkTransmitErrs(rulestest.Selector), []rulestest.Alert{{Name: "NodeNetworkTransmitErrs", For: "1h", Severity: "warning"}}},
+		{NodeHighNumberConntrackEntriesUsed(rulestest.Selector), []rulestest.Alert{{Name: "NodeHighNumberConntrackEntriesUsed", Severity: "warning"}}},
+		{NodeClockNotSynchronising(rulestest.Selector), []rulestest.Alert{{Name: "NodeClockNotSynchronising", For: "10m", Severity: "warning"}}},
+		{NodeCPUHighUsage(rulestest.Selector), []rulestest.Alert{{Name: "NodeCPUHighUsage", For: "15m", Severity: "info"}}},
+		{NodeSystemSaturation(rulestest.Selector), []rulestest.Alert{{Name: "NodeSystemSaturation", For: "15m", Severity: "warning"}}},
+		{NodeMemoryHighUtilization(rulestest.Selector), []rulestest.Alert{{Name: "NodeMemoryHighUtilization", For: "15m", Severity: "warning"}}},
+		{NodeDiskIOSaturation(rulestest.Selector), []rulestest.Alert{{Name: "NodeDiskIOSaturation", For: "30m", Severity: "warning"}}},
+	} {
+		t.Run(tc.want[0].Name, func(t *testing.T) {
+			rulestest.AssertAlerts(t, tc.option, tc.want)
+		})
+	}
+}
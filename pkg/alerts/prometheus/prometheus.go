@@ -0,0 +1,387 @@
+package prometheus
+
+import (
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+// PrometheusBadConfig creates an alerting rule that fires when Prometheus failed to reload its configuration.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_config_last_reload_successful: Whether the last configuration reload attempt was successful
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusBadConfig(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusBadConfig",
+		For:   "10m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Failed Prometheus configuration reload.",
+			"description": "Prometheus {{ $labels.instance }} has failed to reload its configuration.",
+		},
+	}, promql.Metric("prometheus_config_last_reload_successful").MaxOverTime("5m").Equal(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusNotificationQueueRunningFull creates an alerting rule that fires when the alert notification
+// queue is predicted to reach its capacity within the next 30 minutes.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_notifications_queue_length: Number of alerts in the notification queue
+// - prometheus_notifications_queue_capacity: Capacity of the notification queue
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusNotificationQueueRunningFull(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusNotificationQueueRunningFull",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus alert notification queue predicted to run full in less than 30m.",
+			"description": "Alert notification queue of Prometheus {{ $labels.instance }} is running full.",
+		},
+	}, promql.Metric("prometheus_notifications_queue_length").PredictLinear("5m", 60*30).
+		GreaterThan(promql.Metric("prometheus_notifications_queue_capacity").MinOverTime("5m")), labelMatchers)
+}
+
+// PrometheusErrorSendingAlertsToSomeAlertmanagers creates an alerting rule that fires when more than 1%
+// of the alerts sent to an Alertmanager fail.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_notifications_errors_total: Total number of errors sending alert notifications
+// - prometheus_notifications_sent_total: Total number of alert notifications sent
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusErrorSendingAlertsToSomeAlertmanagers(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusErrorSendingAlertsToSomeAlertmanagers",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "More than 1% of alerts sent by Prometheus to a specific Alertmanager were affected by errors.",
+			"description": "{{ printf \"%.1f\" $value }}% errors while sending alerts from Prometheus {{ $labels.instance }} to Alertmanager {{ $labels.alertmanager }}.",
+		},
+	}, promql.Metric("prometheus_notifications_errors_total").Rate("5m").
+		Div(promql.Metric("prometheus_notifications_sent_total").Rate("5m")).
+		Mul(promql.Number(100)).
+		GreaterThan(promql.Number(1)), labelMatchers)
+}
+
+// PrometheusNotConnectedToAlertmanagers creates an alerting rule that fires when Prometheus has not
+// discovered any Alertmanager to send alerts to.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_notifications_alertmanagers_discovered: Number of Alertmanagers discovered and active
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusNotConnectedToAlertmanagers(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusNotConnectedToAlertmanagers",
+		For:   "10m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus is not connected to any Alertmanagers.",
+			"description": "Prometheus {{ $labels.instance }} is not connected to any Alertmanagers.",
+		},
+	}, promql.Metric("prometheus_notifications_alertmanagers_discovered").MaxOverTime("5m").LessThan(promql.Number(1)), labelMatchers)
+}
+
+// PrometheusTSDBReloadsFailing creates an alerting rule that fires when Prometheus failed to reload
+// blocks from disk during the last 3 hours.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_tsdb_reloads_failures_total: Number of failed reloads of blocks from disk
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusTSDBReloadsFailing(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusTSDBReloadsFailing",
+		For:   "4h",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus has issues reloading blocks from disk.",
+			"description": "Prometheus {{ $labels.instance }} has detected {{ $value | humanize }} reload failures over the last 3h.",
+		},
+	}, promql.Metric("prometheus_tsdb_reloads_failures_total").Increase("3h").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusTSDBCompactionsFailing creates an alerting rule that fires when Prometheus failed to compact
+// blocks during the last 3 hours.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_tsdb_compactions_failed_total: Number of failed compactions
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusTSDBCompactionsFailing(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusTSDBCompactionsFailing",
+		For:   "4h",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus has issues compacting blocks.",
+			"description": "Prometheus {{ $labels.instance }} has detected {{ $value | humanize }} compaction failures over the last 3h.",
+		},
+	}, promql.Metric("prometheus_tsdb_compactions_failed_total").Increase("3h").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusDuplicateTimestamps creates an alerting rule that fires when Prometheus drops samples
+// with identical timestamps but different values.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_target_scrapes_sample_duplicate_timestamp_total: Samples rejected due to duplicate timestamps
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusDuplicateTimestamps(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusDuplicateTimestamps",
+		For:   "10m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus is dropping samples with duplicate timestamps.",
+			"description": "Prometheus {{ $labels.instance }} is dropping {{ printf \"%.4g\" $value }} samples/s with different values but duplicated timestamp.",
+		},
+	}, promql.Metric("prometheus_target_scrapes_sample_duplicate_timestamp_total").Rate("5m").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusOutOfOrderTimestamps creates an alerting rule that fires when Prometheus drops samples
+// with timestamps arriving out of order.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_target_scrapes_sample_out_of_order_total: Samples rejected due to being out of order
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusOutOfOrderTimestamps(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusOutOfOrderTimestamps",
+		For:   "10m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus drops samples with out-of-order timestamps.",
+			"description": "Prometheus {{ $labels.instance }} is dropping {{ printf \"%.4g\" $value }} samples/s with timestamps arriving out of order.",
+		},
+	}, promql.Metric("prometheus_target_scrapes_sample_out_of_order_total").Rate("5m").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusRemoteStorageFailures creates an alerting rule that fires when more than 1% of the samples
+// sent to a remote storage fail. Both the current and the pre-2.23 metric names are queried, unless the rule
+// file targets a Prometheus version, see TargetVersion.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_remote_storage_failed_samples_total: Samples which failed on send to remote storage
+// - prometheus_remote_storage_succeeded_samples_total: Samples successfully sent to remote storage
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusRemoteStorageFailures(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusRemoteStorageFailures",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus fails to send samples to remote storage.",
+			"description": "Prometheus {{ $labels.instance }} failed to send {{ printf \"%.1f\" $value }}% of the samples to {{ $labels.remote_name }}:{{ $labels.url }}.",
+		},
+	}, remoteStorageFailedPercentage().GreaterThan(promql.Number(1)), labelMatchers)
+}
+
+// PrometheusRemoteWriteBehind creates an alerting rule that fires when remote write is more than
+// 2 minutes behind the samples ingested by Prometheus.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_remote_storage_highest_timestamp_in_seconds: Highest timestamp ingested by Prometheus
+// - prometheus_remote_storage_queue_highest_sent_timestamp_seconds: Highest timestamp sent by the remote write queue
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusRemoteWriteBehind(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusRemoteWriteBehind",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus remote write is behind.",
+			"description": "Prometheus {{ $labels.instance }} remote write is {{ printf \"%.1f\" $value }}s behind for {{ $labels.remote_name }}:{{ $labels.url }}.",
+		},
+	}, promql.Metric("prometheus_remote_storage_highest_timestamp_in_seconds").MaxOverTime("5m").
+		Sub(promql.Metric("prometheus_remote_storage_queue_highest_sent_timestamp_seconds").MaxOverTime("5m"), promql.Ignoring("remote_name", "url"), promql.GroupRight()).
+		GreaterThan(promql.Number(120)), labelMatchers)
+}
+
+// PrometheusRemoteWriteDesiredShards creates an alerting rule that fires when remote write wants to run
+// more shards than its configured maximum.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_remote_storage_shards_desired: Number of shards the queue wants to run
+// - prometheus_remote_storage_shards_max: Maximum number of shards the queue is allowed to run
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusRemoteWriteDesiredShards(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusRemoteWriteDesiredShards",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus remote write desired shards calculation wants to run more than configured max shards.",
+			"description": "Prometheus {{ $labels.instance }} remote write desired shards calculation wants to run {{ $value }} shards for queue {{ $labels.remote_name }}:{{ $labels.url }}, which is more than the max.",
+		},
+	}, promql.Metric("prometheus_remote_storage_shards_desired").MaxOverTime("5m").
+		GreaterThan(promql.Metric("prometheus_remote_storage_shards_max").MaxOverTime("5m")), labelMatchers)
+}
+
+// PrometheusRuleFailures creates an alerting rule that fires when Prometheus fails to evaluate rules.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_rule_evaluation_failures_total: Number of rule evaluation failures
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusRuleFailures(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusRuleFailures",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus is failing rule evaluations.",
+			"description": "Prometheus {{ $labels.instance }} has failed to evaluate {{ printf \"%.0f\" $value }} rules in the last 5m.",
+		},
+	}, promql.Metric("prometheus_rule_evaluation_failures_total").Increase("5m").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusMissingRuleEvaluations creates an alerting rule that fires when rule group evaluations are
+// skipped because they take longer than their interval.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_rule_group_iterations_missed_total: Number of rule group evaluations missed
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusMissingRuleEvaluations(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusMissingRuleEvaluations",
+		For:   "15m",
+		Labels: map[string]string{
+			"severity": "warning",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus is missing rule evaluations due to slow rule group evaluation.",
+			"description": "Prometheus {{ $labels.instance }} has missed {{ printf \"%.0f\" $value }} rule group evaluations in the last 5m.",
+		},
+	}, promql.Metric("prometheus_rule_group_iterations_missed_total").Increase("5m").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// PrometheusTargetSyncFailure creates an alerting rule that fires when Prometheus failed to create or
+// update the targets of a scrape pool, usually because of an invalid configuration.
+//
+// The alert uses the following Prometheus metrics:
+// - prometheus_target_sync_failed_total: Number of target sync failures per scrape pool
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func PrometheusTargetSyncFailure(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddAlertingRuleQuery(rules.Rule{
+		Alert: "PrometheusTargetSyncFailure",
+		For:   "5m",
+		Labels: map[string]string{
+			"severity": "critical",
+		},
+		Annotations: map[string]string{
+			"summary":     "Prometheus has failed to sync targets.",
+			"description": "{{ printf \"%.0f\" $value }} targets in Prometheus {{ $labels.instance }} have failed to sync because invalid configuration was supplied.",
+		},
+	}, promql.Metric("prometheus_target_sync_failed_total").Increase("30m").GreaterThan(promql.Number(0)), labelMatchers)
+}
+
+// TargetVersion makes the expressions of the rule file select only the names the renamed metrics have in the
+// Prometheus version, such as 2.53, as the panels of the Prometheus dashboards do, see panels.MetricRenames.
+// The expressions are kept as they are when the version is empty. Rule files add it after their groups.
+func TargetVersion(version string) rules.Option {
+	return rules.ExprTransform(func(expr string) (string, error) {
+		return panels.TargetVersionQuery(expr, version)
+	})
+}
+
+// remoteStorageFailedPercentage returns the percentage of the samples sent to the remote storages that failed,
+// from the current or the pre-2.23 metric names, whichever the server exposes.
+func remoteStorageFailedPercentage() promql.Query {
+	failed := promql.Metric("prometheus_remote_storage_failed_samples_total").Rate("5m").
+		Or(promql.Metric("prometheus_remote_storage_samples_failed_total").Rate("5m"))
+	succeeded := promql.Metric("prometheus_remote_storage_succeeded_samples_total").Rate("5m").
+		Or(promql.Metric("prometheus_remote_storage_samples_total").Rate("5m"))
+	return failed.Div(failed.Add(succeeded)).Mul(promql.Number(100))
+}
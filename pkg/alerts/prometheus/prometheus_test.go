@@ -0,0 +1,74 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/rules/rulestest"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+func TestAlerts(t *testing.T) {
+	for _, tc := range []struct {
+		option rules.GroupOption
+		want   []rulestest.Alert
+	}{
+		{PrometheusBadConfig(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusBadConfig", For: "10m", Severity: "critical"}}},
+		{PrometheusNotificationQueueRunningFull(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusNotificationQueueRunningFull", For: "15m", Severity: "warning"}}},
+		{PrometheusErrorSendingAlertsToSomeAlertmanagers(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusErrorSendingAlertsToSomeAlertmanagers", For: "15m", Severity: "warning"}}},
+		{PrometheusNotConnectedToAlertmanagers(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusNotConnectedToAlertmanagers", For: "10m", Severity: "warning"}}},
+		{PrometheusTSDBReloadsFailing(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusTSDBReloadsFailing", For: "4h", Severity: "warning"}}},
+		{PrometheusTSDBCompactionsFailing(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusTSDBCompactionsFailing", For: "4h", Severity: "warning"}}},
+		{PrometheusDuplicateTimestamps(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusDuplicateTimestamps", For: "10m", Severity: "warning"}}},
+		{PrometheusOutOfOrderTimestamps(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusOutOfOrderTimestamps", For: "10m", Severity: "warning"}}},
+		{PrometheusRemoteStorageFailures(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusRemoteStorageFailures", For: "15m", Severity: "critical"}}},
+		{PrometheusRemoteWriteBehind(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusRemoteWriteBehind", For: "15m", Severity: "critical"}}},
+		{PrometheusRemoteWriteDesiredShards(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusRemoteWriteDesiredShards", For: "15m", Severity: "warning"}}},
+		{PrometheusRuleFailures(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusRuleFailures", For: "15m", Severity: "critical"}}},
+		{PrometheusMissingRuleEvaluations(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusMissingRuleEvaluations", For: "15m", Severity: "warning"}}},
+		{PrometheusTargetSyncFailure(rulestest.Selector), []rulestest.Alert{{Name: "PrometheusTargetSyncFailure", For: "5m", Severity: "critical"}}},
+	} {
+		t.Run(tc.want[0].Name, func(t *testing.T) {
+			rulestest.AssertAlerts(t, tc.option, tc.want)
+		})
+	}
+}
+
+func TestTargetVersion(t *testing.T) {
+	const (
+		oldName = "prometheus_remote_storage_failed_samples_total"
+		newName = "prometheus_remote_storage_samples_failed_total"
+	)
+	for _, tc := range []struct {
+		version  string
+		selected []string
+		dropped  []string
+	}{
+		{version: "", selected: []string{oldName, newName}},
+		{version: "2.22.2", selected: []string{oldName}, dropped: []string{newName}},
+		{version: "v2.53", selected: []string{newName}, dropped: []string{oldName}},
+	} {
+		builder, err := rules.New("test",
+			rules.AddRuleGroup("test", PrometheusRemoteStorageFailures()),
+			TargetVersion(tc.version),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expr := builder.RuleFile.Groups[0].Rules[0].Expr
+		for _, name := range tc.selected {
+			if !strings.Contains(expr, name) {
+				t.Errorf("version %q: expected %s in %q", tc.version, name, expr)
+			}
+		}
+		for _, name := range tc.dropped {
+			if strings.Contains(expr, name) {
+				t.Errorf("version %q: expected %s to be dropped from %q", tc.version, name, expr)
+			}
+		}
+	}
+
+	if _, err := rules.New("test", rules.AddRuleGroup("test", PrometheusRemoteStorageFailures()), TargetVersion("2.x")); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
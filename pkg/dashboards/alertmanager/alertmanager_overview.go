@@ -0,0 +1,72 @@
+package alertmanager
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/alertmanager"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func withAlertsGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Alerts",
+		panelgroup.PanelsPerLine(2),
+		panels.Alerts(datasource, labelMatchers),
+		panels.AlertsReceiveRate(datasource, labelMatchers),
+	)
+}
+
+func withNotificationsGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Notifications",
+		panelgroup.PanelsPerLine(2),
+		panels.NotificationsSendRate(datasource, labelMatchers),
+		panels.NotificationDuration(datasource, labelMatchers),
+	)
+}
+
+// Overview builds the Alertmanager / Overview dashboard, showing the alerts received and the notifications
+// sent by Alertmanager, with their failures and latency per integration.
+func Overview(opts ...dashboards.Option) (dashboard.Builder, error) {
+	options, err := dashboards.NewOptions(opts...)
+	if err != nil {
+		return dashboard.Builder{}, err
+	}
+	datasource := options.QueryDatasource()
+	scopeLabelMatchers := helpers.GetScopeLabelMatchers(options.Scopes())
+	variableLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector), scopeLabelMatchers...)
+	panelLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector, "job", "integration"), scopeLabelMatchers...)
+	return options.NewDashboard("alertmanager-overview",
+		dashboard.Name("Alertmanager / Overview"),
+		helpers.AddScopeVariables(datasource, options.Scopes(), "alertmanager_alerts", options.Selector),
+		dashboard.AddVariable("job",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("job",
+					helpers.AddVariableMatcher("alertmanager_alerts", variableLabelMatchers),
+					helpers.AddVariableDatasource(datasource),
+				),
+				listVar.DisplayName("job"),
+			),
+		),
+		helpers.AddRateIntervalVariable(options.RateInterval),
+		dashboard.AddVariable("integration",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("integration",
+					helpers.AddVariableMatcher(
+						"alertmanager_notifications_total",
+						append(variableLabelMatchers, promql.LabelMatcher{Name: "job", Type: "=", Value: "$job"}),
+					),
+					helpers.AddVariableDatasource(datasource),
+				),
+				listVar.AllowAllValue(true),
+				listVar.AllowMultiple(true),
+				listVar.DisplayName("integration"),
+			),
+		),
+		withAlertsGroup(datasource, panelLabelMatchers),
+		withNotificationsGroup(datasource, panelLabelMatchers),
+		helpers.ScopePanels(options.Scopes()),
+	)
+}
@@ -0,0 +1,17 @@
+package alertmanager
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/dashboardstest"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+)
+
+func TestOverview(t *testing.T) {
+	for _, tc := range dashboardstest.ClusterCases(dashboards.Options{Project: "default"}) {
+		t.Run(tc.Name, func(t *testing.T) {
+			builder, err := Overview(dashboards.WithOptions(tc.Options))
+			dashboardstest.AssertGolden(t, "alertmanager-overview-"+tc.Name, builder, err)
+		})
+	}
+}
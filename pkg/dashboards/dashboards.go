@@ -0,0 +1,366 @@
+// Package dashboards holds the options of the dashboard constructors of the mixin packages, such as
+// prometheus.Overview, so that Go programs can build the community dashboards and customise them:
+//
+//	builder, err := prometheus.Overview(
+//		dashboards.Project("monitoring"),
+//		dashboards.Datasource("thanos"),
+//		dashboards.AddPanelGroup("Custom", panels.PrometheusHeadSeries("thanos", nil)),
+//	)
+package dashboards
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	internalPromql "github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	labelNamesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-names"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	promqlVar "github.com/perses/perses/go-sdk/prometheus/variable/promql"
+	"github.com/perses/perses/go-sdk/variable"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+	staticListVar "github.com/perses/perses/go-sdk/variable/plugin/static-list"
+	v1Dashboard "github.com/perses/perses/pkg/model/api/v1/dashboard"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// DatasourceVariable is the name of the dashboard variable selecting the datasource of the panels and
+	// variables, added when the dashboard can switch between several datasources.
+	DatasourceVariable = "datasource"
+	// RateIntervalVariable is the name of the dashboard variable holding the range of the rates in the panels.
+	RateIntervalVariable = "interval"
+	// DefaultRateInterval lets Perses pick a range suited to the scrape interval and the time range.
+	DefaultRateInterval = "$__rate_interval"
+)
+
+// rateIntervalRegexp matches the references to the rate interval variable, $interval and ${interval}.
+var rateIntervalRegexp = regexp.MustCompile(`\$(?:` + RateIntervalVariable + `\b|\{` + RateIntervalVariable + `\})`)
+
+// Options holds the settings shared by every dashboard constructor.
+type Options struct {
+	// Project is the Perses project the dashboard belongs to.
+	Project string
+	// Datasource is the name of the Prometheus datasource queried by the panels and variables.
+	// The project default datasource is used when empty. With Datasources, it is the default value of the
+	// datasource variable.
+	Datasource string
+	// Datasources lists the Prometheus datasources the dashboard can switch between. When set, the dashboard
+	// gets a datasource variable offering them, and the panels and variables query the selected one.
+	Datasources []string
+	// ClusterLabelName is the label identifying the cluster of a series. The dashboard gets a cluster
+	// variable filtering every query when it is set.
+	ClusterLabelName string
+	// ScopeLabels are labels scoping the series further, such as region or namespace. Each one gets a
+	// variable named after it, filtering every query, after the cluster variable.
+	ScopeLabels []string
+	// Version is the version of the exporter the dashboard targets, such as 2.53 for Prometheus, so that the
+	// queries only select the names the renamed metrics have in that version. The queries select both names
+	// when empty.
+	Version string
+	// RateInterval is the default value of the rate interval variable used as range by every rate in
+	// the panels. It is either a fixed window such as 1m or $__rate_interval, the default. The dashboards
+	// without the variable use it as range instead.
+	RateInterval string
+	// Selector holds the label matchers selecting the series of the mixin, such as job="node". They are
+	// injected in every query, replacing the matchers the queries already have on the same labels.
+	Selector []promql.LabelMatcher
+	// Extra holds the dashboard options applied after the ones of the constructor, adding panel groups or
+	// replacing variables.
+	Extra []dashboard.Option
+}
+
+// Scope is a label scoping the series of a dashboard, such as the cluster, filtered by a variable. The
+// variable of a scope offers the values left by the variables of the previous scopes, and allows several
+// values and all of them, so that the dashboard can show several clusters at once.
+type Scope struct {
+	Label    string
+	Variable string
+}
+
+// Scopes returns the scopes of the dashboard: the cluster label, with the cluster variable, then the scope
+// labels, with variables named after them.
+func (o Options) Scopes() []Scope {
+	var scopes []Scope
+	if o.ClusterLabelName != "" {
+		scopes = append(scopes, Scope{Label: o.ClusterLabelName, Variable: "cluster"})
+	}
+	for _, label := range o.ScopeLabels {
+		scopes = append(scopes, Scope{Label: label, Variable: label})
+	}
+	return scopes
+}
+
+// Option sets a setting of the dashboard built by a constructor.
+type Option func(options *Options) error
+
+// NewOptions returns the settings given by the options, in the project named default unless set.
+func NewOptions(options ...Option) (Options, error) {
+	o := Options{Project: "default"}
+	for _, opt := range options {
+		if err := opt(&o); err != nil {
+			return o, err
+		}
+	}
+	return o, nil
+}
+
+// NewDashboard creates the dashboard of a constructor, applying the extra options after its own ones. The
+// datasource variable comes first, since the other variables query the datasource it selects.
+func (o Options) NewDashboard(name string, options ...dashboard.Option) (dashboard.Builder, error) {
+	defaults := []dashboard.Option{dashboard.ProjectName(o.Project)}
+	if len(o.Datasources) > 0 {
+		defaultValue := o.Datasource
+		if defaultValue == "" {
+			defaultValue = o.Datasources[0]
+		}
+		defaults = append(defaults, dashboard.AddVariable(DatasourceVariable,
+			listVar.List(
+				StaticList(o.Datasources...),
+				listVar.DefaultValue(defaultValue),
+				listVar.DisplayName("datasource"),
+			),
+		))
+	}
+	return dashboard.New(name, append(append(append(defaults, options...), o.Extra...), checkVariableNames, setVariableMatchTypes, o.setRateInterval)...)
+}
+
+// setVariableMatchTypes sets the match type of the matchers on the list variables of the dashboard, in the
+// panel queries and the Prometheus variables, to a regex one for the variables allowing several values or
+// all of them, and to an equality one for the other variables, see promql.SetVariableMatchTypes.
+func setVariableMatchTypes(builder *dashboard.Builder) error {
+	multiValue := map[string]bool{}
+	for _, v := range builder.Dashboard.Spec.Variables {
+		if list, ok := v.Spec.(*v1Dashboard.ListVariableSpec); ok {
+			multiValue[list.Name] = list.AllowMultiple || list.AllowAllValue
+		}
+	}
+	set := func(queries []string) error {
+		for i, query := range queries {
+			q, err := internalPromql.SetVariableMatchTypes(query, multiValue)
+			if err != nil {
+				return err
+			}
+			queries[i] = q
+		}
+		return nil
+	}
+
+	for _, v := range builder.Dashboard.Spec.Variables {
+		list, ok := v.Spec.(*v1Dashboard.ListVariableSpec)
+		if !ok {
+			continue
+		}
+		var err error
+		switch plugin := list.Plugin.Spec.(type) {
+		case labelValuesVar.Builder:
+			err = set(plugin.Matchers)
+		case labelNamesVar.Builder:
+			err = set(plugin.Matchers)
+		case promqlVar.Builder:
+			exprs := []string{plugin.Expr}
+			err = set(exprs)
+			plugin.Expr = exprs[0]
+			list.Plugin.Spec = plugin
+		}
+		if err != nil {
+			return fmt.Errorf("variable %q: %w", list.Name, err)
+		}
+	}
+
+	for _, p := range builder.Dashboard.Spec.Panels {
+		for i, q := range p.Spec.Queries {
+			plugin, ok := q.Spec.Plugin.Spec.(query.Builder)
+			if !ok {
+				continue
+			}
+			queries := []string{plugin.Query}
+			if err := set(queries); err != nil {
+				return fmt.Errorf("panel %q: %w", p.Spec.Display.Name, err)
+			}
+			plugin.Query = queries[0]
+			p.Spec.Queries[i].Spec.Plugin.Spec = plugin
+		}
+	}
+	return nil
+}
+
+// setRateInterval replaces the rate interval variable in the panel queries of the dashboards lacking it, such
+// as the USE Method one whose panels query recording rules, with the default rate interval, so that the rates
+// of the queries added to their panels, for instance with panels.ExtraQuery, still have a range.
+func (o Options) setRateInterval(builder *dashboard.Builder) error {
+	for _, v := range builder.Dashboard.Spec.Variables {
+		if v.Spec.GetName() == RateIntervalVariable {
+			return nil
+		}
+	}
+	rateInterval := o.RateInterval
+	if rateInterval == "" {
+		rateInterval = DefaultRateInterval
+	}
+	if !strings.HasPrefix(rateInterval, "$") {
+		if _, err := model.ParseDuration(rateInterval); err != nil {
+			return fmt.Errorf("invalid rate interval %q: %w", rateInterval, err)
+		}
+	}
+	for _, p := range builder.Dashboard.Spec.Panels {
+		for i, q := range p.Spec.Queries {
+			plugin, ok := q.Spec.Plugin.Spec.(query.Builder)
+			if !ok {
+				continue
+			}
+			plugin.Query = rateIntervalRegexp.ReplaceAllLiteralString(plugin.Query, rateInterval)
+			p.Spec.Queries[i].Spec.Plugin.Spec = plugin
+		}
+	}
+	return nil
+}
+
+// checkVariableNames fails when two variables have the same name, such as a scope label named after a
+// variable of the dashboard.
+func checkVariableNames(builder *dashboard.Builder) error {
+	names := map[string]bool{}
+	for _, v := range builder.Dashboard.Spec.Variables {
+		name := v.Spec.GetName()
+		if names[name] {
+			return fmt.Errorf("variable %q defined twice", name)
+		}
+		names[name] = true
+	}
+	return nil
+}
+
+// QueryDatasource returns the datasource the panels and variables query: the datasource variable when the
+// dashboard has one, the datasource otherwise.
+func (o Options) QueryDatasource() string {
+	if len(o.Datasources) > 0 {
+		return "$" + DatasourceVariable
+	}
+	return o.Datasource
+}
+
+// WithOptions replaces the settings set by the previous options, as the generator does with the settings of
+// its configuration file.
+func WithOptions(options Options) Option {
+	return func(o *Options) error {
+		*o = options
+		return nil
+	}
+}
+
+// Project sets the Perses project of the dashboard.
+func Project(name string) Option {
+	return func(o *Options) error {
+		o.Project = name
+		return nil
+	}
+}
+
+// Datasource sets the Prometheus datasource of the panels and variables.
+func Datasource(name string) Option {
+	return func(o *Options) error {
+		o.Datasource = name
+		return nil
+	}
+}
+
+// Datasources adds a datasource variable switching the panels and variables between the datasources.
+func Datasources(names ...string) Option {
+	return func(o *Options) error {
+		o.Datasources = names
+		return nil
+	}
+}
+
+// ClusterLabelName adds a cluster variable filtering every query on the label.
+func ClusterLabelName(name string) Option {
+	return func(o *Options) error {
+		o.ClusterLabelName = name
+		return nil
+	}
+}
+
+// ScopeLabels adds a variable filtering every query per label, such as region or namespace, after the
+// cluster variable.
+func ScopeLabels(labels ...string) Option {
+	return func(o *Options) error {
+		o.ScopeLabels = labels
+		return nil
+	}
+}
+
+// Version sets the version of the exporter the dashboard targets, such as 2.53 for Prometheus.
+func Version(version string) Option {
+	return func(o *Options) error {
+		o.Version = version
+		return nil
+	}
+}
+
+// RateInterval sets the default range of the rates in the panels, such as 1m.
+func RateInterval(interval string) Option {
+	return func(o *Options) error {
+		o.RateInterval = interval
+		return nil
+	}
+}
+
+// Selector sets the label matchers selecting the series of the mixin, given as the inside of a PromQL
+// selector such as job="node",namespace=~"monitoring|default".
+func Selector(selector string) Option {
+	return func(o *Options) error {
+		labelMatchers, err := internalPromql.ParseSelector(selector)
+		if err != nil {
+			return err
+		}
+		o.Selector = labelMatchers
+		return nil
+	}
+}
+
+// With applies dashboard options after the ones of the constructor.
+func With(options ...dashboard.Option) Option {
+	return func(o *Options) error {
+		o.Extra = append(o.Extra, options...)
+		return nil
+	}
+}
+
+// AddPanelGroup adds a panel group after the ones of the constructor.
+func AddPanelGroup(title string, options ...panelgroup.Option) Option {
+	return With(dashboard.AddPanelGroup(title, options...))
+}
+
+// Variable replaces the variable of the dashboard with the same name, keeping its position, or adds the
+// variable after the other ones when the dashboard has none with that name.
+func Variable(name string, options ...variable.Option) Option {
+	return With(func(builder *dashboard.Builder) error {
+		var added dashboard.Builder
+		if err := dashboard.AddVariable(name, options...)(&added); err != nil {
+			return err
+		}
+		v := added.Dashboard.Spec.Variables[0]
+		for i, existing := range builder.Dashboard.Spec.Variables {
+			if existing.Spec.GetName() == name {
+				builder.Dashboard.Spec.Variables[i] = v
+				return nil
+			}
+		}
+		builder.Dashboard.Spec.Variables = append(builder.Dashboard.Spec.Variables, v)
+		return nil
+	})
+}
+
+// StaticList sets the static list plugin of a list variable. Unlike the SDK builder, whose spec lacks the
+// inline yaml tag, it does not nest the values under a pluginspec key in the YAML output.
+func StaticList(values ...string) listVar.Option {
+	return func(builder *listVar.Builder) error {
+		builder.ListVariableSpec.Plugin.Kind = "StaticListVariable"
+		builder.ListVariableSpec.Plugin.Spec = staticListVar.PluginSpec{Values: values}
+		return nil
+	}
+}
@@ -0,0 +1,255 @@
+package dashboards_test
+
+import (
+	"flag"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards/alertmanager"
+	nodeexporter "github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards/node_exporter"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards/prometheus"
+	panelsSdk "github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/perses/perses/go-sdk/dashboard"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+	staticListVar "github.com/perses/perses/go-sdk/variable/plugin/static-list"
+	v1Dashboard "github.com/perses/perses/pkg/model/api/v1/dashboard"
+)
+
+func TestOptions(t *testing.T) {
+	builder, err := prometheus.Overview(
+		dashboards.Project("monitoring"),
+		dashboards.Datasource("thanos"),
+		dashboards.AddPanelGroup("Custom", panels.PrometheusHeadSeries("thanos", nil)),
+		dashboards.Variable("job", listVar.List(staticListVar.StaticList(staticListVar.Values("prometheus")))),
+		dashboards.Variable("team", listVar.List(staticListVar.StaticList(staticListVar.Values("sre")))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := builder.Dashboard
+
+	if d.Metadata.Project != "monitoring" {
+		t.Errorf("expected project monitoring, got %q", d.Metadata.Project)
+	}
+	if title := d.Spec.Layouts[len(d.Spec.Layouts)-1].Spec.(v1Dashboard.GridLayoutSpec).Display.Title; title != "Custom" {
+		t.Errorf("expected the Custom panel group last, got %q", title)
+	}
+
+	var names []string
+	for _, v := range d.Spec.Variables {
+		names = append(names, v.Spec.GetName())
+	}
+	if want := []string{"job", "interval", "instance", "team"}; !slices.Equal(names, want) {
+		t.Fatalf("expected variables %v, got %v", want, names)
+	}
+	if kind := d.Spec.Variables[0].Spec.(*v1Dashboard.ListVariableSpec).Plugin.Kind; kind != "StaticListVariable" {
+		t.Errorf("expected the job variable to be replaced by a static list, got %q", kind)
+	}
+}
+
+func TestSelector(t *testing.T) {
+	options, err := dashboards.NewOptions(dashboards.Selector(`job="node",namespace=~"monitoring|default"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []promql.LabelMatcher{{Name: "job", Type: "=", Value: "node"}, {Name: "namespace", Type: "=~", Value: "monitoring|default"}}
+	if !slices.Equal(options.Selector, want) {
+		t.Errorf("expected the selector %v, got %v", want, options.Selector)
+	}
+
+	// The matchers can also be set without parsing a selector.
+	builder, err := prometheus.Overview(dashboards.WithOptions(dashboards.Options{Project: "default", Selector: want[1:]}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugin := builder.Dashboard.Spec.Panels["0_0"].Spec.Queries[0].Spec.Plugin.Spec.(query.Builder)
+	if !strings.Contains(plugin.Query, `namespace=~"monitoring|default"`) {
+		t.Errorf("expected the selector in the query %s", plugin.Query)
+	}
+
+	if _, err := prometheus.Overview(dashboards.Selector(`job=`)); err == nil {
+		t.Error("expected an error for an invalid selector")
+	}
+}
+
+func TestWith(t *testing.T) {
+	builder, err := prometheus.RemoteWrite(dashboards.With(dashboard.Name("Remote Write")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := builder.Dashboard.Spec.Display.Name; name != "Remote Write" {
+		t.Errorf("expected the display name to be overridden, got %q", name)
+	}
+}
+
+func TestDatasources(t *testing.T) {
+	builder, err := prometheus.Overview(dashboards.Datasources("prometheus", "thanos"), dashboards.Datasource("thanos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := builder.Dashboard
+
+	first := d.Spec.Variables[0].Spec.(*v1Dashboard.ListVariableSpec)
+	if first.Name != dashboards.DatasourceVariable || first.DefaultValue == nil || first.DefaultValue.SingleValue != "thanos" {
+		t.Fatalf("expected the datasource variable first with thanos as default value, got %+v", first)
+	}
+	for _, p := range d.Spec.Panels {
+		for _, q := range p.Spec.Queries {
+			if name := q.Spec.Plugin.Spec.(query.Builder).Datasource.Name; name != "$datasource" {
+				t.Errorf("panel %q: expected the datasource variable, got %q", p.Spec.Display.Name, name)
+			}
+		}
+	}
+}
+
+func TestScopeLabels(t *testing.T) {
+	builder, err := prometheus.RemoteWrite(dashboards.ClusterLabelName("k8s_cluster"), dashboards.ScopeLabels("region", "namespace"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, v := range builder.Dashboard.Spec.Variables {
+		names = append(names, v.Spec.GetName())
+	}
+	if want := []string{"cluster", "region", "namespace"}; !slices.Equal(names[:3], want) {
+		t.Fatalf("expected the scope variables %v first, got %v", want, names)
+	}
+	namespace := builder.Dashboard.Spec.Variables[2].Spec.(*v1Dashboard.ListVariableSpec)
+	if !namespace.AllowMultiple || !namespace.AllowAllValue {
+		t.Errorf("expected the scope variables to allow several values and all of them, got %+v", namespace)
+	}
+
+	if _, err := prometheus.Overview(dashboards.ScopeLabels("job")); err == nil {
+		t.Error("expected an error for a scope label named after a variable of the dashboard")
+	}
+}
+
+func TestVariableMatchTypes(t *testing.T) {
+	builder, err := prometheus.Overview(dashboards.Variable("instance",
+		listVar.List(
+			staticListVar.StaticList(staticListVar.Values("localhost:9090", "localhost:9091")),
+			listVar.AllowMultiple(true),
+		),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range builder.Dashboard.Spec.Panels {
+		for _, q := range p.Spec.Queries {
+			expr := q.Spec.Plugin.Spec.(query.Builder).Query
+			if strings.Contains(expr, `instance="$instance"`) || strings.Contains(expr, `job=~"$job"`) {
+				t.Errorf("panel %q: expected instance=~ and job= matchers, got %q", p.Spec.Display.Name, expr)
+			}
+		}
+	}
+}
+
+// builders are the constructors of every dashboard of the mixins.
+var builders = map[string]func(opts ...dashboards.Option) (dashboard.Builder, error){
+	"prometheus-overview":              prometheus.Overview,
+	"prometheus-remote-write":          prometheus.RemoteWrite,
+	"node-exporter-nodes":              nodeexporter.Nodes,
+	"node-exporter-cluster-use-method": nodeexporter.ClusterUseMethod,
+	"alertmanager-overview":            alertmanager.Overview,
+}
+
+// TestRateInterval checks that the rates of every dashboard have a range, including the ones of the extra
+// queries added with panel options: the rate interval variable, or the rate interval itself in the dashboards
+// lacking the variable.
+func TestRateInterval(t *testing.T) {
+	for name, build := range builders {
+		builder, err := build(
+			dashboards.RateInterval("2m"),
+			dashboards.AddPanelGroup("Custom",
+				panels.PrometheusHeadSeries("", nil, panelsSdk.ExtraQuery("rate(prometheus_tsdb_head_series_created_total[5m])")),
+			),
+		)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		hasVariable := slices.ContainsFunc(builder.Dashboard.Spec.Variables, func(v v1Dashboard.Variable) bool {
+			return v.Spec.GetName() == "interval"
+		})
+		want := "[2m]"
+		if hasVariable {
+			want = "[$interval]"
+		}
+		for _, p := range builder.Dashboard.Spec.Panels {
+			for _, q := range p.Spec.Queries {
+				expr := q.Spec.Plugin.Spec.(query.Builder).Query
+				if strings.Contains(expr, "rate(") && !strings.Contains(expr, want) {
+					t.Errorf("%s: panel %q: expected the range %s, got %q", name, p.Spec.Display.Name, want, expr)
+				}
+			}
+		}
+	}
+}
+
+// TestVersion checks that no panel of any dashboard selects a name the renamed metrics do not have in the
+// targeted Prometheus version, whichever group the panel is in.
+func TestVersion(t *testing.T) {
+	for _, version := range []string{"2.22", "2.53"} {
+		v, err := panels.ParseVersion(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var unexposed []string
+		for _, rename := range panels.MetricRenames {
+			if v.Before(rename.Version) {
+				unexposed = append(unexposed, rename.New)
+			} else {
+				unexposed = append(unexposed, rename.Old)
+			}
+		}
+		unexposedRegexp := regexp.MustCompile(`\b(` + strings.Join(unexposed, "|") + `)\b`)
+
+		for name, build := range builders {
+			builder, err := build(dashboards.Version(version))
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			for _, p := range builder.Dashboard.Spec.Panels {
+				for _, q := range p.Spec.Queries {
+					if metric := unexposedRegexp.FindString(q.Spec.Plugin.Spec.(query.Builder).Query); metric != "" {
+						t.Errorf("%s, version %s: panel %q selects %s", name, version, p.Spec.Display.Name, metric)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestFlags checks that importing the dashboards registers no command line flag, so that a program can define
+// its own, such as -output.
+func TestFlags(t *testing.T) {
+	flag.VisitAll(func(f *flag.Flag) {
+		// The testing flags and the -update flag of the golden files are the only ones of the test binary.
+		if !strings.HasPrefix(f.Name, "test.") && f.Name != "update" {
+			t.Errorf("flag -%s registered by an imported package", f.Name)
+		}
+	})
+}
+
+// TestDependencies checks that the public packages do not depend on the code of the command, which writes the
+// dashboards and rules, applies them to the Perses API and reads its configuration.
+func TestDependencies(t *testing.T) {
+	output, err := exec.Command("go", "list", "-deps", "../...").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dependency := range strings.Fields(string(output)) {
+		for _, forbidden := range []string{"internal/config", "internal/dashboards", "internal/grafana", "internal/kustomize", "internal/perses", "internal/rules"} {
+			if strings.HasSuffix(dependency, "/community-perses-dashboards/"+forbidden) {
+				t.Errorf("the public packages depend on %s", dependency)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package nodeexporter
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/node_exporter"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func withNodeExporterNodesCPU(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("CPU",
+		panelgroup.PanelsPerLine(2),
+		panels.NodeCPUUsagePercentage(datasource, labelMatchers),
+		panels.NodeAverage(datasource, labelMatchers),
+	)
+}
+
+func withNodeExporterNodesMemory(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Memory",
+		panelgroup.PanelsPerLine(2),
+		panels.NodeMemoryUsageBytes(datasource, labelMatchers),
+		panels.NodeMemoryUsagePercentage(datasource, labelMatchers),
+	)
+}
+
+func withNodeExporterNodesDisk(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Disk",
+		panelgroup.PanelsPerLine(2),
+		panels.NodeDiskIOBytes(datasource, labelMatchers),
+		panels.NodeDiskIOSeconds(datasource, labelMatchers),
+	)
+}
+
+func withNodeExporterNodesNetwork(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Network",
+		panelgroup.PanelsPerLine(2),
+		panels.NodeNetworkReceivedBytes(datasource, labelMatchers),
+		panels.NodeNetworkTransmitedBytes(datasource, labelMatchers),
+	)
+}
+
+// Nodes builds the Node Exporter / Nodes dashboard, showing the CPU, load, memory, disk and network usage
+// of a single node.
+func Nodes(opts ...dashboards.Option) (dashboard.Builder, error) {
+	options, err := dashboards.NewOptions(opts...)
+	if err != nil {
+		return dashboard.Builder{}, err
+	}
+	datasource := options.QueryDatasource()
+	scopeLabelMatchers := helpers.GetScopeLabelMatchers(options.Scopes())
+	variableLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector), scopeLabelMatchers...)
+	panelLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector, "instance"), scopeLabelMatchers...)
+	return options.NewDashboard("node-exporter-nodes",
+		dashboard.Name("Node Exporter / Nodes"),
+		helpers.AddScopeVariables(datasource, options.Scopes(), "node_uname_info{sysname!='Darwin'}", options.Selector),
+		helpers.AddRateIntervalVariable(options.RateInterval),
+		dashboard.AddVariable("instance",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("instance",
+					helpers.AddVariableDatasource(datasource),
+					helpers.AddVariableMatcher(
+						"node_uname_info{sysname!='Darwin'}",
+						variableLabelMatchers,
+					),
+				),
+				listVar.DisplayName("instance"),
+				listVar.AllowAllValue(true),
+			),
+		),
+		withNodeExporterNodesCPU(datasource, panelLabelMatchers),
+		withNodeExporterNodesMemory(datasource, panelLabelMatchers),
+		withNodeExporterNodesDisk(datasource, panelLabelMatchers),
+		withNodeExporterNodesNetwork(datasource, panelLabelMatchers),
+		helpers.ScopePanels(options.Scopes()),
+	)
+}
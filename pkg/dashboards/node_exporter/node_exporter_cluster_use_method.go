@@ -0,0 +1,93 @@
+package nodeexporter
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/node_exporter"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func withClusterCPU(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("CPU",
+		panelgroup.PanelsPerLine(2),
+		panels.ClusterNodeCPUUsagePercentage(datasource, labelMatchers),
+		panels.ClusterNodeCPUSaturationPercentage(datasource, labelMatchers),
+	)
+}
+
+func withClusterMemory(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Memory",
+		panelgroup.PanelsPerLine(2),
+		panels.ClusterNodeMemoryUsagePercentage(datasource, labelMatchers),
+		panels.ClusterNodeMemorySaturationPercentage(datasource, labelMatchers),
+	)
+}
+
+func withClusterNetwork(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Network",
+		panelgroup.PanelsPerLine(2),
+		panels.ClusterNodeNetworkUsageBytes(datasource, labelMatchers),
+		panels.ClusterNodeNetworkSaturationBytes(datasource, labelMatchers),
+	)
+}
+
+func withClusterDiskIO(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Disk IO",
+		panelgroup.PanelsPerLine(2),
+		panels.ClusterNodeDiskUsagePercentage(datasource, labelMatchers),
+		panels.ClusterNodeDiskSaturationPercentage(datasource, labelMatchers),
+	)
+}
+
+func withClusterDiskSpace(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Disk Space",
+		panelgroup.PanelsPerLine(1),
+		panels.ClusterNodeDiskSpacePercentage(datasource, labelMatchers),
+	)
+}
+
+// ClusterUseMethod builds the Node Exporter / USE Method / Cluster dashboard, showing the utilisation and
+// saturation of the CPU, memory, network and disks of every node.
+func ClusterUseMethod(opts ...dashboards.Option) (dashboard.Builder, error) {
+	options, err := dashboards.NewOptions(opts...)
+	if err != nil {
+		return dashboard.Builder{}, err
+	}
+	datasource := options.QueryDatasource()
+	scopeLabelMatchers := helpers.GetScopeLabelMatchers(options.Scopes())
+	instanceLabelMatcher := promql.LabelMatcher{
+		Name:  "instance",
+		Value: "$instance",
+		Type:  "=~",
+	}
+	variableLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector), scopeLabelMatchers...)
+	panelLabelMatchers := append(append(helpers.GetSelectorLabelMatchers(options.Selector, "instance"), scopeLabelMatchers...), instanceLabelMatcher)
+	return options.NewDashboard("node-exporter-cluster-use-method",
+		dashboard.Name("Node Exporter / USE Method / Cluster"),
+		helpers.AddScopeVariables(datasource, options.Scopes(), "node_uname_info{sysname!='Darwin'}", options.Selector),
+		dashboard.AddVariable("instance",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("instance",
+					helpers.AddVariableDatasource(datasource),
+					helpers.AddVariableMatcher(
+						"node_uname_info{sysname!='Darwin'}",
+						variableLabelMatchers,
+					),
+				),
+				listVar.DisplayName("instance"),
+				listVar.AllowAllValue(true),
+				listVar.AllowMultiple(true),
+			),
+		),
+		withClusterCPU(datasource, panelLabelMatchers),
+		withClusterMemory(datasource, panelLabelMatchers),
+		withClusterNetwork(datasource, panelLabelMatchers),
+		withClusterDiskIO(datasource, panelLabelMatchers),
+		withClusterDiskSpace(datasource, panelLabelMatchers),
+		helpers.ScopePanels(options.Scopes()),
+	)
+}
@@ -0,0 +1,33 @@
+package nodeexporter
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/dashboardstest"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+)
+
+// options are the default options of the Node Exporter mixin, selecting the node job.
+var options = dashboards.Options{
+	Project:  "default",
+	Selector: []promql.LabelMatcher{{Name: "job", Type: "=", Value: "node"}},
+}
+
+func TestNodes(t *testing.T) {
+	for _, tc := range dashboardstest.ClusterCases(options) {
+		t.Run(tc.Name, func(t *testing.T) {
+			builder, err := Nodes(dashboards.WithOptions(tc.Options))
+			dashboardstest.AssertGolden(t, "node-exporter-nodes-"+tc.Name, builder, err)
+		})
+	}
+}
+
+func TestClusterUseMethod(t *testing.T) {
+	for _, tc := range dashboardstest.ClusterCases(options) {
+		t.Run(tc.Name, func(t *testing.T) {
+			builder, err := ClusterUseMethod(dashboards.WithOptions(tc.Options))
+			dashboardstest.AssertGolden(t, "node-exporter-cluster-use-method-"+tc.Name, builder, err)
+		})
+	}
+}
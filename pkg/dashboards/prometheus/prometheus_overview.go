@@ -0,0 +1,99 @@
+package prometheus
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func withPrometheusOverviewStatsGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Prometheus Stats",
+		panelgroup.PanelsPerLine(1),
+		panels.PrometheusStatsTable(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusOverviewDiscoveryGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Discovery",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusTargetSync(datasource, labelMatchers),
+		panels.PrometheusTargets(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusRetrievalGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Retrieval",
+		panelgroup.PanelsPerLine(3),
+		panels.PrometheusAverageScrapeIntervalDuration(datasource, labelMatchers),
+		panels.PrometheusScrapeFailures(datasource, labelMatchers),
+		panels.PrometheusAppendedSamples(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusStorageGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Storage",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusHeadSeries(datasource, labelMatchers),
+		panels.PrometheusHeadChunks(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusQueryGroup(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Query",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusQueryRate(datasource, labelMatchers),
+		panels.PrometheusQueryStateDuration(datasource, labelMatchers),
+	)
+}
+
+// Overview builds the Prometheus / Overview dashboard, showing the target discovery, scrapes, storage and
+// query statistics of Prometheus servers.
+func Overview(opts ...dashboards.Option) (dashboard.Builder, error) {
+	options, err := dashboards.NewOptions(opts...)
+	if err != nil {
+		return dashboard.Builder{}, err
+	}
+	datasource := options.QueryDatasource()
+	scopeLabelMatchers := helpers.GetScopeLabelMatchers(options.Scopes())
+	variableLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector), scopeLabelMatchers...)
+	panelLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector, "job", "instance"), scopeLabelMatchers...)
+	return options.NewDashboard("prometheus-overview",
+		dashboard.Name("Prometheus / Overview"),
+		helpers.AddScopeVariables(datasource, options.Scopes(), "prometheus_build_info", options.Selector),
+		dashboard.AddVariable("job",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("job",
+					helpers.AddVariableMatcher("prometheus_build_info", variableLabelMatchers),
+					helpers.AddVariableDatasource(datasource),
+				),
+				listVar.DisplayName("job"),
+			),
+		),
+		helpers.AddRateIntervalVariable(options.RateInterval),
+		dashboard.AddVariable("instance",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("instance",
+					helpers.AddVariableMatcher(
+						"prometheus_build_info",
+						append(variableLabelMatchers, promql.LabelMatcher{Name: "job", Type: "=", Value: "$job"}),
+					),
+					helpers.AddVariableDatasource(datasource),
+				),
+				listVar.DisplayName("instance"),
+			),
+		),
+		withPrometheusOverviewStatsGroup(datasource, panelLabelMatchers),
+		withPrometheusOverviewDiscoveryGroup(datasource, panelLabelMatchers),
+		withPrometheusRetrievalGroup(datasource, panelLabelMatchers),
+		withPrometheusStorageGroup(datasource, panelLabelMatchers),
+		withPrometheusQueryGroup(datasource, panelLabelMatchers),
+		helpers.ScopePanels(options.Scopes()),
+		targetVersion(options.Version),
+	)
+}
@@ -0,0 +1,113 @@
+package prometheus
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+	panels "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	"github.com/perses/perses/go-sdk/dashboard"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	labelValuesVar "github.com/perses/perses/go-sdk/prometheus/variable/label-values"
+	listVar "github.com/perses/perses/go-sdk/variable/list-variable"
+)
+
+func withPrometheusRwTimestamps(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Timestamps",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusRemoteStorageTimestampLag(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageRateLag(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusRwSamples(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Samples",
+		panelgroup.PanelsPerLine(1),
+		panels.PrometheusRemoteStorageSampleRate(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusRwShard(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Shards",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusRemoteStorageCurrentShards(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageDesiredShards(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageMaxShards(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageMinShards(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusRwShardDetails(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Shard Details",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusRemoteStorageShardCapacity(datasource, labelMatchers),
+		panels.PrometheusRemoteStoragePendingSamples(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusRwSegments(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Segments",
+		panelgroup.PanelsPerLine(2),
+		panels.PrometheusTSDBCurrentSegment(datasource, labelMatchers),
+		panels.PrometheusRemoteWriteCurrentSegment(datasource, labelMatchers),
+	)
+}
+
+func withPrometheusRwMiscRates(datasource string, labelMatchers []promql.LabelMatcher) dashboard.Option {
+	return dashboard.AddPanelGroup("Misc. Rates",
+		panelgroup.PanelsPerLine(4),
+		panels.PrometheusRemoteStorageDroppedSamplesRate(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageFailedSamplesRate(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageRetriedSamplesRate(datasource, labelMatchers),
+		panels.PrometheusRemoteStorageEnqueueRetriesRate(datasource, labelMatchers),
+	)
+}
+
+// RemoteWrite builds the Prometheus / Remote Write dashboard, showing the lag, throughput, shards and failed
+// samples of the remote write queues of Prometheus servers.
+func RemoteWrite(opts ...dashboards.Option) (dashboard.Builder, error) {
+	options, err := dashboards.NewOptions(opts...)
+	if err != nil {
+		return dashboard.Builder{}, err
+	}
+	datasource := options.QueryDatasource()
+	scopeLabelMatchers := helpers.GetScopeLabelMatchers(options.Scopes())
+	variableLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector), scopeLabelMatchers...)
+	panelLabelMatchers := append(helpers.GetSelectorLabelMatchers(options.Selector, "instance", "url"), scopeLabelMatchers...)
+	return options.NewDashboard("prometheus-remote-write",
+		dashboard.Name("Prometheus / Remote Write"),
+		helpers.AddScopeVariables(datasource, options.Scopes(), "prometheus_remote_storage_shards", options.Selector),
+		helpers.AddRateIntervalVariable(options.RateInterval),
+		dashboard.AddVariable("instance",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("instance",
+					helpers.AddVariableMatcher(
+						"prometheus_remote_storage_shards",
+						variableLabelMatchers,
+					),
+					helpers.AddVariableDatasource(datasource),
+				),
+				listVar.DisplayName("instance"),
+			),
+		),
+		dashboard.AddVariable("url",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues("url",
+					helpers.AddVariableMatcher(
+						"prometheus_remote_storage_shards{instance='$instance'}",
+						append(helpers.GetSelectorLabelMatchers(options.Selector, "instance"), scopeLabelMatchers...),
+					),
+					helpers.AddVariableDatasource(datasource),
+				),
+				listVar.DisplayName("url"),
+			),
+		),
+		withPrometheusRwTimestamps(datasource, panelLabelMatchers),
+		withPrometheusRwSamples(datasource, panelLabelMatchers),
+		withPrometheusRwShard(datasource, panelLabelMatchers),
+		withPrometheusRwShardDetails(datasource, panelLabelMatchers),
+		withPrometheusRwSegments(datasource, panelLabelMatchers),
+		withPrometheusRwMiscRates(datasource, panelLabelMatchers),
+		helpers.ScopePanels(options.Scopes()),
+		targetVersion(options.Version),
+	)
+}
@@ -0,0 +1,28 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/dashboardstest"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/dashboards"
+)
+
+func TestOverview(t *testing.T) {
+	for _, tc := range dashboardstest.ClusterCases(dashboards.Options{Project: "default"}) {
+		t.Run(tc.Name, func(t *testing.T) {
+			builder, err := Overview(dashboards.WithOptions(tc.Options))
+			dashboardstest.AssertGolden(t, "prometheus-overview-"+tc.Name, builder, err)
+		})
+	}
+}
+
+func TestRemoteWrite(t *testing.T) {
+	cases := dashboardstest.ClusterCases(dashboards.Options{Project: "default"})
+	cases = append(cases, dashboardstest.Case{Name: "v2.22", Options: dashboards.Options{Project: "default", Version: "2.22"}})
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			builder, err := RemoteWrite(dashboards.WithOptions(tc.Options))
+			dashboardstest.AssertGolden(t, "prometheus-remote-write-"+tc.Name, builder, err)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package prometheus
+
+import (
+	"fmt"
+
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
+	panelsPrometheus "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	"github.com/perses/perses/go-sdk/dashboard"
+	"github.com/perses/perses/go-sdk/panel"
+)
+
+// targetVersion applies panels.TargetVersion with the version of the options to every panel of the dashboard,
+// so that no panel querying a renamed metric is left selecting both names. Dashboards add it after their
+// panel groups.
+func targetVersion(version string) dashboard.Option {
+	return func(builder *dashboard.Builder) error {
+		for _, p := range builder.Dashboard.Spec.Panels {
+			b := &panels.Builder{Builder: &panel.Builder{Panel: *p}}
+			if err := panelsPrometheus.TargetVersion(version)(b); err != nil {
+				return fmt.Errorf("panel %q: %w", p.Spec.Display.Name, err)
+			}
+			*p = b.Panel
+		}
+		return nil
+	}
+}
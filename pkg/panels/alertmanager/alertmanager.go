@@ -1,8 +1,9 @@
 package alertmanager
 
 import (
-	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
 	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
 	commonSdk "github.com/perses/perses/go-sdk/common"
 	"github.com/perses/perses/go-sdk/panel"
 	panelgroup "github.com/perses/perses/go-sdk/panel-group"
@@ -19,11 +20,12 @@ import (
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the query.
+//   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: A panel option that can be added to a panel group.
-func Alerts(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func Alerts(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Alerts",
 		panel.Description("Shows current alerts in Alertmanager"),
 		timeSeriesPanel.Chart(
@@ -33,16 +35,13 @@ func Alerts(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgr
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum(alertmanager_alerts{job=~'$job'}) by (instance)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Alertmanager - Alerts"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(alertmanager_alerts{job=~'$job'}) by (instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Alertmanager - Alerts"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -58,10 +57,11 @@ func Alerts(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgr
 // Parameters:
 //   - datasourceName: The name of the data source to be used for the query.
 //   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func AlertsReceiveRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func AlertsReceiveRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Alerts receive rate",
 		panel.Description("Shows alert receive rate in Alertmanager"),
 		timeSeriesPanel.Chart(
@@ -71,26 +71,19 @@ func AlertsReceiveRate(datasourceName string, labelMatchers ...promql.LabelMatch
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum(rate(alertmanager_alerts_received_total{job=~'$job'}[5m])) by (job,instance)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Alertmanager - Received"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(rate(alertmanager_alerts_received_total{job=~'$job'}[5m])) by (job,instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Alertmanager - Received"),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum(rate(alertmanager_alerts_invalid_total{job=~'$job'}[5m])) by (job,instance)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Alertmanager - Invalid"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(rate(alertmanager_alerts_invalid_total{job=~'$job'}[5m])) by (job,instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Alertmanager - Invalid"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -111,11 +104,12 @@ func AlertsReceiveRate(datasourceName string, labelMatchers ...promql.LabelMatch
 //
 // Parameters:
 // - datasourceName: The name of the data source to be used for the queries.
-// - labelMatchers: A variadic parameter for Prometheus label matchers to filter the queries.
+// - labelMatchers: Optional Prometheus label matchers to filter the queries.
+// - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 // - panelgroup.Option: The configured panel option.
-func NotificationsSendRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NotificationsSendRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Notifications Send Rate",
 		panel.Description("Shows notification send rate for the Alertmanager"),
 		timeSeriesPanel.Chart(
@@ -125,26 +119,19 @@ func NotificationsSendRate(datasourceName string, labelMatchers ...promql.LabelM
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum(rate(alertmanager_notifications_total{job=~'$job', integration=~'$integration'}[5m])) by (integration, instance)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{integration}} - Total"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(rate(alertmanager_notifications_total{job=~'$job', integration=~'$integration'}[5m])) by (integration, instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{integration}} - Total"),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum(rate(alertmanager_notifications_failed_total{job=~'$job', integration=~'$integration'}[5m])) by (integration, instance)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{integration}} - Failed"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(rate(alertmanager_notifications_failed_total{job=~'$job', integration=~'$integration'}[5m])) by (integration, instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{integration}} - Failed"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -164,11 +151,12 @@ func NotificationsSendRate(datasourceName string, labelMatchers ...promql.LabelM
 //
 // Parameters:
 //   - datasourceName: The name of the data source to be used for the queries.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the metrics.
+//   - labelMatchers: Optional Prometheus label matchers to filter the metrics.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: An option that adds the configured panel to a panel group.
-func NotificationDuration(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NotificationDuration(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Notification Duration",
 		panel.Description("Shows notification latency for the Alertmanager"),
 		timeSeriesPanel.Chart(
@@ -183,35 +171,24 @@ func NotificationDuration(datasourceName string, labelMatchers ...promql.LabelMa
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"histogram_quantile(0.99, sum(rate(alertmanager_notification_latency_seconds_bucket{job=~'$job', integration=~'$integration'}[5m])) by (le,integration,instance))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{integration}} - 99th Percentile"),
-			),
+		helpers.AddPrometheusQuery(
+			"histogram_quantile(0.99, sum(rate(alertmanager_notification_latency_seconds_bucket{job=~'$job', integration=~'$integration'}[5m])) by (le,integration,instance))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{integration}} - 99th Percentile"),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"histogram_quantile(0.50, sum(rate(alertmanager_notification_latency_seconds_bucket{job=~'$job', integration=~'$integration'}[5m])) by (le,integration,instance))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{integration}} - Median"),
-			),
+		helpers.AddPrometheusQuery(
+			"histogram_quantile(0.50, sum(rate(alertmanager_notification_latency_seconds_bucket{job=~'$job', integration=~'$integration'}[5m])) by (le,integration,instance))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{integration}} - Median"),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum(rate(alertmanager_notification_latency_seconds_sum{job=~'$job', integration=~'$integration'}[5m])) by (integration,instance) / sum(rate(alertmanager_notification_latency_seconds_count{job=~'$job', integration=~'$integration'}[5m])) by (integration,instance)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{integration}} - Average"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(rate(alertmanager_notification_latency_seconds_sum{job=~'$job', integration=~'$integration'}[5m])) by (integration,instance) / sum(rate(alertmanager_notification_latency_seconds_count{job=~'$job', integration=~'$integration'}[5m])) by (integration,instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{integration}} - Average"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
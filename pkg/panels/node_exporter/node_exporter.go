@@ -1,8 +1,10 @@
 package nodeexporter
 
 import (
-	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
-	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	rules "github.com/nicolastakashi/community-perses-dashboards/pkg/rules/node_exporter"
 	commonSdk "github.com/perses/perses/go-sdk/common"
 	"github.com/perses/perses/go-sdk/panel"
 	panelgroup "github.com/perses/perses/go-sdk/panel-group"
@@ -24,11 +26,12 @@ import (
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the query.
+//   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: A panel option that can be added to a panel group.
-func NodeCPUUsagePercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeCPUUsagePercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("CPU Usage",
 		panel.Description("Shows CPU utilization percentage across cluster nodes"),
 		timeSeriesPanel.Chart(
@@ -43,16 +46,15 @@ func NodeCPUUsagePercentage(datasourceName string, labelMatchers ...promql.Label
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"((1 - sum without (mode) (rate(node_cpu_seconds_total{job='node', mode=~'idle|iowait|steal', instance='$instance'}[5m]))) / ignoring(cpu) group_left count without (cpu, mode) (node_cpu_seconds_total{job='node', mode='idle', instance='$instance'}))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{device}} - CPU - Usage"),
-			),
+		helpers.AddQuery(
+			promql.Number(1).Sub(rules.CPUIdleRate("5m")).
+				Div(rules.NumCPU(), promql.Ignoring("cpu"), promql.GroupLeft()).
+				Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{device}} - CPU - Usage"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -68,11 +70,12 @@ func NodeCPUUsagePercentage(datasourceName string, labelMatchers ...promql.Label
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers.
+//   - labelMatchers: Optional Prometheus label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func ClusterNodeCPUUsagePercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeCPUUsagePercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("CPU Usage",
 		timeSeriesPanel.Chart(
 			timeSeriesPanel.WithYAxis(timeSeriesPanel.YAxis{
@@ -86,16 +89,14 @@ func ClusterNodeCPUUsagePercentage(datasourceName string, labelMatchers ...promq
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"((instance:node_cpu_utilisation:rate5m{job='node'} * instance:node_num_cpu:sum{job='node'}) != 0 ) / scalar(sum(instance:node_num_cpu:sum{job='node'}))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			promql.Metric(rules.NodeCPUUtilisationRate5m).Mul(promql.Metric(rules.NodeNumCPUSum)).NotEqual(promql.Number(0)).
+				Div(promql.Metric(rules.NodeNumCPUSum).Sum().Scalar()),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -114,11 +115,12 @@ func ClusterNodeCPUUsagePercentage(datasourceName string, labelMatchers ...promq
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the query.
+//   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: A panel option that can be added to a panel group.
-func ClusterNodeCPUSaturationPercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeCPUSaturationPercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("CPU Saturation (Load1 per CPU)",
 		panel.Description("Shows CPU saturation metrics across cluster nodes"),
 		timeSeriesPanel.Chart(
@@ -133,16 +135,13 @@ func ClusterNodeCPUSaturationPercentage(datasourceName string, labelMatchers ...
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"(instance:node_load1_per_cpu:ratio{job='node'} / scalar(count(instance:node_load1_per_cpu:ratio{job='node'})))  != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			nonZeroShare(rules.NodeLoad1PerCPURatio),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -157,11 +156,12 @@ func ClusterNodeCPUSaturationPercentage(datasourceName string, labelMatchers ...
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers.
+//   - labelMatchers: Optional Prometheus label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func ClusterNodeMemoryUsagePercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeMemoryUsagePercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Memory Utilisation",
 		panel.Description("Shows memory utilization percentage across cluster nodes"),
 		timeSeriesPanel.Chart(
@@ -176,16 +176,13 @@ func ClusterNodeMemoryUsagePercentage(datasourceName string, labelMatchers ...pr
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"(instance:node_memory_utilisation:ratio{job='node'} / scalar(count(instance:node_memory_utilisation:ratio{job='node'}))) != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			nonZeroShare(rules.NodeMemoryUtilisationRatio),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -204,11 +201,12 @@ func ClusterNodeMemoryUsagePercentage(datasourceName string, labelMatchers ...pr
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the query.
+//   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: A panel option that can be added to a panel group.
-func ClusterNodeMemorySaturationPercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeMemorySaturationPercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Memory Saturation (Major Page Faults)",
 		panel.Description("Shows memory saturation through page fault metrics"),
 		timeSeriesPanel.Chart(
@@ -223,16 +221,13 @@ func ClusterNodeMemorySaturationPercentage(datasourceName string, labelMatchers
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"instance:node_vmstat_pgmajfault:rate5m{job='node'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			promql.Metric(rules.NodeVmstatPgmajfaultRate5m),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -251,11 +246,12 @@ func ClusterNodeMemorySaturationPercentage(datasourceName string, labelMatchers
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus data source.
-//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the query.
+//   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: A panel option that can be added to a panel group.
-func ClusterNodeDiskUsagePercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeDiskUsagePercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Disk IO Utilisation",
 		panel.Description("Shows disk I/O utilization across cluster nodes"),
 		timeSeriesPanel.Chart(
@@ -270,16 +266,13 @@ func ClusterNodeDiskUsagePercentage(datasourceName string, labelMatchers ...prom
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"(instance_device:node_disk_io_time_seconds:rate5m{job='node'} / scalar(count(instance_device:node_disk_io_time_seconds:rate5m{job='node'}))) != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			nonZeroShare(rules.NodeDiskIOTimeSecondsRate5m),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -291,7 +284,7 @@ func ClusterNodeDiskUsagePercentage(datasourceName string, labelMatchers ...prom
 // The panel shows:
 // - Disk I/O saturation per device
 // - Saturation patterns across nodes
-func ClusterNodeDiskSaturationPercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeDiskSaturationPercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Disk IO Saturation",
 		panel.Description("Shows disk I/O saturation metrics"),
 		timeSeriesPanel.Chart(
@@ -306,16 +299,13 @@ func ClusterNodeDiskSaturationPercentage(datasourceName string, labelMatchers ..
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"(instance_device:node_disk_io_time_seconds:rate5m{job='node'} / scalar(count(instance_device:node_disk_io_time_seconds:rate5m{job='node'}))) != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			nonZeroShare(rules.NodeDiskIOTimeSecondsRate5m),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -328,7 +318,7 @@ func ClusterNodeDiskSaturationPercentage(datasourceName string, labelMatchers ..
 // The panel shows:
 // - Disk space utilization percentage
 // - Available vs total space ratio
-func ClusterNodeDiskSpacePercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeDiskSpacePercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Disk Space Utilisation",
 		panel.Description("Shows disk space utilization metrics"),
 		timeSeriesPanel.Chart(
@@ -343,16 +333,18 @@ func ClusterNodeDiskSpacePercentage(datasourceName string, labelMatchers ...prom
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"sum without (device) (max without (fstype, mountpoint) ((node_filesystem_size_bytes{job='node', fstype!='', mountpoint!=''} - node_filesystem_avail_bytes{job='node', fstype!='', mountpoint!=''}) != 0)) / scalar(sum(max without (fstype, mountpoint) (node_filesystem_size_bytes{job='node', fstype!='', mountpoint!=''})))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}}"),
-			),
+		helpers.AddQuery(
+			promql.Metric("node_filesystem_size_bytes", filesystemMatchers...).
+				Sub(promql.Metric("node_filesystem_avail_bytes", filesystemMatchers...)).
+				NotEqual(promql.Number(0)).
+				MaxWithout("fstype", "mountpoint").
+				SumWithout("device").
+				Div(promql.Metric("node_filesystem_size_bytes", filesystemMatchers...).MaxWithout("fstype", "mountpoint").Sum().Scalar()),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -364,7 +356,7 @@ func ClusterNodeDiskSpacePercentage(datasourceName string, labelMatchers ...prom
 // The panel shows:
 // - Network packet drops per interface
 // - Drop rates across nodes
-func ClusterNodeNetworkSaturationBytes(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeNetworkSaturationBytes(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Network Saturation (Drops Receive/Transmit)",
 		panel.Description("Shows network saturation through drop metrics"),
 		timeSeriesPanel.Chart(
@@ -379,16 +371,13 @@ func ClusterNodeNetworkSaturationBytes(datasourceName string, labelMatchers ...p
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"instance:node_network_receive_drop_excluding_lo:rate5m{job='node'} != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Network - Received"),
-			),
+		helpers.AddQuery(
+			promql.Metric(rules.NodeNetworkReceiveDropExcludingLoRate5m).NotEqual(promql.Number(0)),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Network - Received"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -401,7 +390,7 @@ func ClusterNodeNetworkSaturationBytes(datasourceName string, labelMatchers ...p
 // The panel shows:
 // - Network throughput per interface
 // - Receive and transmit rates
-func ClusterNodeNetworkUsageBytes(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func ClusterNodeNetworkUsageBytes(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Network Utilisation (Bytes Receive/Transmit)",
 		panel.Description("Shows network utilization in bytes"),
 		timeSeriesPanel.Chart(
@@ -416,26 +405,19 @@ func ClusterNodeNetworkUsageBytes(datasourceName string, labelMatchers ...promql
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"instance:node_network_receive_bytes_excluding_lo:rate5m{job='node'} != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Network - Received"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"instance:node_network_transmit_bytes_excluding_lo:rate5m{job='node'} != 0",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Network - Transmitted"),
-			),
+		helpers.AddQuery(
+			promql.Metric(rules.NodeNetworkReceiveBytesExcludingLoRate5m).NotEqual(promql.Number(0)),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Network - Received"),
 		),
+		helpers.AddQuery(
+			promql.Metric(rules.NodeNetworkTransmitBytesExcludingLoRate5m).NotEqual(promql.Number(0)),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Network - Transmitted"),
+		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -454,10 +436,11 @@ func ClusterNodeNetworkUsageBytes(datasourceName string, labelMatchers ...promql
 // Parameters:
 //   - datasourceName: The name of the data source to be used for the queries.
 //   - labelMatchers: Optional Prometheus label matchers to filter the metrics.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel group option.
-func NodeAverage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeAverage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("CPU Usage",
 		panel.Description("Shows CPU utilization metrics"),
 		timeSeriesPanel.Chart(
@@ -467,46 +450,33 @@ func NodeAverage(datasourceName string, labelMatchers ...promql.LabelMatcher) pa
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"node_load1{job='node', instance='$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("CPU - 1m Average"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"node_load5{job='node', instance='$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("CPU - 5m Average"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"node_load15{job='node', instance='$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("CPU - 15m Average"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"count(node_cpu_seconds_total{job='node', instance='$instance', mode='idle'})",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("CPU - Logical Cores"),
-			),
-		),
+		helpers.AddQuery(
+			promql.Metric("node_load1").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("CPU - 1m Average"),
+		),
+		helpers.AddQuery(
+			promql.Metric("node_load5").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("CPU - 5m Average"),
+		),
+		helpers.AddQuery(
+			promql.Metric("node_load15").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("CPU - 15m Average"),
+		),
+		helpers.AddQuery(
+			promql.Metric("node_cpu_seconds_total").
+				Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}, promql.LabelMatcher{Name: "mode", Type: "=", Value: "idle"}).
+				Count(),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("CPU - Logical Cores"),
+		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -523,10 +493,11 @@ func NodeAverage(datasourceName string, labelMatchers ...promql.LabelMatcher) pa
 // Parameters:
 // - datasourceName: The name of the data source to be used for the queries.
 // - labelMatchers: Optional Prometheus label matchers to filter the queries.
+// - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 // - panelgroup.Option: The configured panel group option.
-func NodeMemoryUsageBytes(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeMemoryUsageBytes(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Memory Usage",
 		panel.Description("Shows memory utilization metrics"),
 		timeSeriesPanel.Chart(
@@ -542,36 +513,25 @@ func NodeMemoryUsageBytes(datasourceName string, labelMatchers ...promql.LabelMa
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"node_memory_Buffers_bytes{job='node', instance='$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("Memory - Buffers"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"node_memory_Cached_bytes{job='node', instance='$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("Memory - Cached"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"node_memory_MemFree_bytes{job='node', instance='$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("Memory - Free"),
-			),
+		helpers.AddQuery(
+			promql.Metric("node_memory_Buffers_bytes").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("Memory - Buffers"),
 		),
+		helpers.AddQuery(
+			promql.Metric("node_memory_Cached_bytes").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("Memory - Cached"),
+		),
+		helpers.AddQuery(
+			promql.Metric("node_memory_MemFree_bytes").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("Memory - Free"),
+		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -587,10 +547,11 @@ func NodeMemoryUsageBytes(datasourceName string, labelMatchers ...promql.LabelMa
 // Parameters:
 // - datasourceName: The name of the Prometheus datasource.
 // - labelMatchers: Optional Prometheus label matchers to filter the metrics.
+// - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 // - panelgroup.Option: The panel option for the memory usage gauge chart.
-func NodeMemoryUsagePercentage(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeMemoryUsagePercentage(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Memory Usage",
 		panel.Description("Shows memory utilization across nodes"),
 		gauge.Chart(
@@ -613,16 +574,17 @@ func NodeMemoryUsagePercentage(datasourceName string, labelMatchers ...promql.La
 				},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"100 - (avg(node_memory_MemAvailable_bytes{job='node', instance='$instance'}) / avg(node_memory_MemTotal_bytes{job='node', instance='$instance'}) * 100)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("Memory - Usage"),
-			),
+		helpers.AddQuery(
+			promql.Number(100).Sub(
+				promql.Metric("node_memory_MemAvailable_bytes").Avg().
+					Div(promql.Metric("node_memory_MemTotal_bytes").Avg()).
+					Mul(promql.Number(100)),
+			).Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("Memory - Usage"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -639,10 +601,11 @@ func NodeMemoryUsagePercentage(datasourceName string, labelMatchers ...promql.La
 // Parameters:
 //   - datasourceName: The name of the data source.
 //   - labelMatchers: Optional Prometheus label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func NodeDiskIOBytes(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeDiskIOBytes(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Disk I/O Bytes",
 		panel.Description("Shows disk I/O metrics in bytes"),
 		timeSeriesPanel.Chart(
@@ -657,26 +620,19 @@ func NodeDiskIOBytes(datasourceName string, labelMatchers ...promql.LabelMatcher
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(node_disk_read_bytes_total{job='node', instance='$instance',device!=''}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{device}} - Disk - Usage"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(node_disk_io_time_seconds_total{job='node', instance='$instance',device!=''}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{device}} - Disk - Written"),
-			),
+		helpers.AddQuery(
+			promql.Metric("node_disk_read_bytes_total", promql.LabelMatcher{Name: "device", Type: "!=", Value: ""}).Rate("5m").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{device}} - Disk - Usage"),
 		),
+		helpers.AddQuery(
+			promql.Metric("node_disk_io_time_seconds_total", promql.LabelMatcher{Name: "device", Type: "!=", Value: ""}).Rate("5m").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{device}} - Disk - Written"),
+		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -688,7 +644,7 @@ func NodeDiskIOBytes(datasourceName string, labelMatchers ...promql.LabelMatcher
 // The panel shows:
 // - I/O operation duration per device
 // - Time spent on disk operations
-func NodeDiskIOSeconds(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeDiskIOSeconds(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Disk I/O Seconds",
 		panel.Description("Shows disk I/O duration metrics"),
 		timeSeriesPanel.Chart(
@@ -703,16 +659,13 @@ func NodeDiskIOSeconds(datasourceName string, labelMatchers ...promql.LabelMatch
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(node_disk_io_time_seconds_total{job='node', instance='$instance',device!=''}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{device}} - Disk - IO Time"),
-			),
+		helpers.AddQuery(
+			promql.Metric("node_disk_io_time_seconds_total", promql.LabelMatcher{Name: "device", Type: "!=", Value: ""}).Rate("5m").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{device}} - Disk - IO Time"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -729,10 +682,11 @@ func NodeDiskIOSeconds(datasourceName string, labelMatchers ...promql.LabelMatch
 // Parameters:
 //   - datasourceName: The name of the data source to be used for the query.
 //   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func NodeNetworkReceivedBytes(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeNetworkReceivedBytes(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Network Received",
 		panel.Description("Shows network received bytes metrics"),
 		timeSeriesPanel.Chart(
@@ -747,16 +701,13 @@ func NodeNetworkReceivedBytes(datasourceName string, labelMatchers ...promql.Lab
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(node_network_receive_bytes_total{job='node', instance='$instance',device!='lo'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{device}} - Network - Received"),
-			),
+		helpers.AddQuery(
+			rules.NetworkReceiveBytesRate("5m").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{device}} - Network - Received"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -773,10 +724,11 @@ func NodeNetworkReceivedBytes(datasourceName string, labelMatchers ...promql.Lab
 // Parameters:
 //   - datasourceName: The name of the data source to be used for the query.
 //   - labelMatchers: Optional Prometheus label matchers to filter the query.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: A panel option that can be added to a panel group.
-func NodeNetworkTransmitedBytes(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func NodeNetworkTransmitedBytes(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Network Transmitted",
 		panel.Description("Shows network transmitted bytes metrics"),
 		timeSeriesPanel.Chart(
@@ -791,15 +743,24 @@ func NodeNetworkTransmitedBytes(datasourceName string, labelMatchers ...promql.L
 				Values:   []commonSdk.Calculation{commonSdk.LastCalculation},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(node_network_transmit_bytes_total{job='node', instance='$instance',device!='lo'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{device}} - Network - Transmitted"),
-			),
+		helpers.AddQuery(
+			rules.NetworkTransmitBytesRate("5m").Where(promql.LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{device}} - Network - Transmitted"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
+
+// filesystemMatchers select the series of the mounted filesystems.
+var filesystemMatchers = []promql.LabelMatcher{
+	{Name: "fstype", Type: "!=", Value: ""},
+	{Name: "mountpoint", Type: "!=", Value: ""},
+}
+
+// nonZeroShare returns each series of the metric divided by the number of series, so that the stacked
+// series add up to their average, leaving out the series at 0.
+func nonZeroShare(metric string) promql.Query {
+	return promql.Metric(metric).Div(promql.Metric(metric).Count().Scalar()).NotEqual(promql.Number(0))
+}
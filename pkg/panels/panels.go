@@ -0,0 +1,184 @@
+// Package panels holds the options accepted by every panel of the library, customising the defaults the
+// panels of the mixin packages are created with:
+//
+//	prometheus.PrometheusHeadSeries(datasource, labelMatchers,
+//		panels.Title("Series"),
+//		panels.Thresholds(commonSdk.Thresholds{Steps: []commonSdk.StepOption{{Value: 1e6, Color: "red"}}}),
+//	)
+package panels
+
+import (
+	"fmt"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	commonSdk "github.com/perses/perses/go-sdk/common"
+	"github.com/perses/perses/go-sdk/panel"
+	"github.com/perses/perses/go-sdk/panel/gauge"
+	timeSeriesPanel "github.com/perses/perses/go-sdk/panel/time-series"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+)
+
+// Option customises a panel of the library once its defaults are set.
+type Option func(builder *Builder) error
+
+// Builder is the panel being customised, along with the datasource and the label matchers it was created
+// with, which the extra queries use as well.
+type Builder struct {
+	*panel.Builder
+	Datasource    string
+	LabelMatchers []promql.LabelMatcher
+}
+
+// Apply returns the panel option applying the options in order. Every panel of the library adds it after its
+// own options, so that the options override the defaults.
+func Apply(datasourceName string, labelMatchers []promql.LabelMatcher, options []Option) panel.Option {
+	return func(builder *panel.Builder) error {
+		b := &Builder{Builder: builder, Datasource: datasourceName, LabelMatchers: labelMatchers}
+		for _, opt := range options {
+			if err := opt(b); err != nil {
+				return fmt.Errorf("panel %q: %w", builder.Spec.Display.Name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// Title replaces the title of the panel.
+func Title(title string) Option {
+	return func(builder *Builder) error {
+		builder.Spec.Display.Name = title
+		return nil
+	}
+}
+
+// Description replaces the description of the panel.
+func Description(description string) Option {
+	return func(builder *Builder) error {
+		builder.Spec.Display.Description = description
+		return nil
+	}
+}
+
+// Unit sets the unit of the values of a time series or gauge panel, such as bytes or percent-decimal.
+func Unit(unit string) Option {
+	return func(builder *Builder) error {
+		switch spec := builder.Spec.Plugin.Spec.(type) {
+		case timeSeriesPanel.PluginSpec:
+			if spec.YAxis == nil {
+				spec.YAxis = &timeSeriesPanel.YAxis{}
+			}
+			spec.YAxis.Format = withUnit(spec.YAxis.Format, unit)
+			builder.Spec.Plugin.Spec = spec
+		case gauge.PluginSpec:
+			spec.Format = withUnit(spec.Format, unit)
+			builder.Spec.Plugin.Spec = spec
+		default:
+			return unsupported("unit", builder)
+		}
+		return nil
+	}
+}
+
+// withUnit returns a copy of the format with the unit, keeping its decimal places and short values.
+func withUnit(format *commonSdk.Format, unit string) *commonSdk.Format {
+	f := commonSdk.Format{}
+	if format != nil {
+		f = *format
+	}
+	f.Unit = unit
+	return &f
+}
+
+// Legend replaces the legend of a time series panel, such as its position and its list or table mode.
+func Legend(legend timeSeriesPanel.Legend) Option {
+	return func(builder *Builder) error {
+		spec, ok := builder.Spec.Plugin.Spec.(timeSeriesPanel.PluginSpec)
+		if !ok {
+			return unsupported("legend", builder)
+		}
+		spec.Legend = &legend
+		builder.Spec.Plugin.Spec = spec
+		return nil
+	}
+}
+
+// Thresholds sets the thresholds of a time series or gauge panel.
+func Thresholds(thresholds commonSdk.Thresholds) Option {
+	return func(builder *Builder) error {
+		switch spec := builder.Spec.Plugin.Spec.(type) {
+		case timeSeriesPanel.PluginSpec:
+			spec.Thresholds = &thresholds
+			builder.Spec.Plugin.Spec = spec
+		case gauge.PluginSpec:
+			spec.Thresholds = &thresholds
+			builder.Spec.Plugin.Spec = spec
+		default:
+			return unsupported("thresholds", builder)
+		}
+		return nil
+	}
+}
+
+// ExtraQuery adds a PromQL query to the panel, with the datasource and the label matchers of the panel and
+// the rate interval variable as range of its rates, as the queries of the panel have.
+func ExtraQuery(expr string, options ...query.Option) Option {
+	return func(builder *Builder) error {
+		options = append([]query.Option{helpers.AddQueryDataSource(builder.Datasource)}, options...)
+		return helpers.AddPrometheusQuery(expr, builder.LabelMatchers, options...)(builder.Builder)
+	}
+}
+
+// SeriesNameFormat replaces the legend format of every query of the panel, such as {{instance}}.
+func SeriesNameFormat(format string) Option {
+	return func(builder *Builder) error {
+		return updateQueries(builder, func(spec *query.PluginSpec) error {
+			spec.SeriesNameFormat = format
+			return nil
+		})
+	}
+}
+
+// QueryTransform rewrites the expression of every query of the panel, for instance to wrap it into an
+// aggregation. The expressions given to the transform already hold the label matchers of the panel.
+func QueryTransform(transform func(expr string) (string, error)) Option {
+	return func(builder *Builder) error {
+		return updateQueries(builder, func(spec *query.PluginSpec) error {
+			expr, err := transform(spec.Query)
+			if err != nil {
+				return err
+			}
+			spec.Query = expr
+			return nil
+		})
+	}
+}
+
+// ScopeLabels keeps the labels, such as the cluster label, in the aggregations and vector matchings of every
+// query of the panel, and shows them in the series names, for dashboards selecting several clusters at once.
+func ScopeLabels(labels ...string) Option {
+	return func(builder *Builder) error {
+		return updateQueries(builder, func(spec *query.PluginSpec) error {
+			return helpers.ScopeQuery(spec, labels)
+		})
+	}
+}
+
+// updateQueries updates the plugin spec of every PromQL query of the panel.
+func updateQueries(builder *Builder, update func(spec *query.PluginSpec) error) error {
+	for i, q := range builder.Spec.Queries {
+		plugin, ok := q.Spec.Plugin.Spec.(query.Builder)
+		if !ok {
+			return fmt.Errorf("query %d is not a PromQL query", i+1)
+		}
+		if err := update(&plugin.PluginSpec); err != nil {
+			return fmt.Errorf("query %d: %w", i+1, err)
+		}
+		builder.Spec.Queries[i].Spec.Plugin.Spec = plugin
+	}
+	return nil
+}
+
+func unsupported(option string, builder *Builder) error {
+	return fmt.Errorf("the %s plugin has no %s", builder.Spec.Plugin.Kind, option)
+}
@@ -0,0 +1,130 @@
+package panels_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
+	nodeexporter "github.com/nicolastakashi/community-perses-dashboards/pkg/panels/node_exporter"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels/prometheus"
+	commonSdk "github.com/perses/perses/go-sdk/common"
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	"github.com/perses/perses/go-sdk/panel/gauge"
+	timeSeriesPanel "github.com/perses/perses/go-sdk/panel/time-series"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+	v1 "github.com/perses/perses/pkg/model/api/v1"
+)
+
+var labelMatchers = []promql.LabelMatcher{{Name: "job", Type: "=", Value: "prometheus"}}
+
+// buildPanel builds the panel added to a panel group by the option of a panel of the library.
+func buildPanel(t *testing.T, option panelgroup.Option) (v1.Panel, error) {
+	t.Helper()
+	group, err := panelgroup.New("test", option)
+	if err != nil {
+		return v1.Panel{}, err
+	}
+	if len(group.Panels) != 1 {
+		t.Fatalf("expected a single panel, got %d", len(group.Panels))
+	}
+	return group.Panels[0], nil
+}
+
+func queries(p v1.Panel) []query.PluginSpec {
+	var specs []query.PluginSpec
+	for _, q := range p.Spec.Queries {
+		specs = append(specs, q.Spec.Plugin.Spec.(query.Builder).PluginSpec)
+	}
+	return specs
+}
+
+func TestDefaults(t *testing.T) {
+	p, err := buildPanel(t, prometheus.PrometheusHeadSeries("prometheus", labelMatchers))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Spec.Display.Name != "Head Series" {
+		t.Errorf("expected the default title, got %q", p.Spec.Display.Name)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	legend := timeSeriesPanel.Legend{Position: timeSeriesPanel.RightPosition, Mode: timeSeriesPanel.ListMode}
+	thresholds := commonSdk.Thresholds{Steps: []commonSdk.StepOption{{Value: 1e6, Color: "red"}}}
+	p, err := buildPanel(t, prometheus.PrometheusHeadSeries("prometheus", labelMatchers,
+		panels.Title("Series"),
+		panels.Description("Series in the head block"),
+		panels.Unit(commonSdk.DecimalUnit),
+		panels.Legend(legend),
+		panels.Thresholds(thresholds),
+		panels.ExtraQuery("sum(prometheus_tsdb_head_series)"),
+		panels.SeriesNameFormat("{{instance}}"),
+		panels.QueryTransform(func(expr string) (string, error) { return "max(" + expr + ")", nil }),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Spec.Display.Name != "Series" || p.Spec.Display.Description != "Series in the head block" {
+		t.Errorf("unexpected display %+v", p.Spec.Display)
+	}
+	spec := p.Spec.Plugin.Spec.(timeSeriesPanel.PluginSpec)
+	if spec.YAxis == nil || spec.YAxis.Format == nil || spec.YAxis.Format.Unit != commonSdk.DecimalUnit {
+		t.Errorf("unexpected y axis %+v", spec.YAxis)
+	}
+	if spec.Legend == nil || spec.Legend.Position != legend.Position || spec.Legend.Mode != legend.Mode {
+		t.Errorf("unexpected legend %+v", spec.Legend)
+	}
+	if spec.Thresholds == nil || spec.Thresholds.Steps[0].Value != 1e6 {
+		t.Errorf("unexpected thresholds %+v", spec.Thresholds)
+	}
+
+	specs := queries(p)
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(specs))
+	}
+	for _, spec := range specs {
+		if spec.SeriesNameFormat != "{{instance}}" {
+			t.Errorf("unexpected series name format %q", spec.SeriesNameFormat)
+		}
+		if !strings.HasPrefix(spec.Query, "max(") || !strings.Contains(spec.Query, `job="prometheus"`) {
+			t.Errorf("expected a transformed query with the label matchers, got %q", spec.Query)
+		}
+		if spec.Datasource == nil || spec.Datasource.Name != "prometheus" {
+			t.Errorf("expected the panel datasource, got %+v", spec.Datasource)
+		}
+	}
+}
+
+func TestGaugeOptions(t *testing.T) {
+	p, err := buildPanel(t, nodeexporter.NodeMemoryUsagePercentage("", nil, panels.Unit(string(commonSdk.PercentUnit))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := p.Spec.Plugin.Spec.(gauge.PluginSpec)
+	if spec.Format == nil || spec.Format.Unit != string(commonSdk.PercentUnit) || spec.Thresholds == nil {
+		t.Errorf("expected the unit to be replaced and the thresholds kept, got %+v", spec)
+	}
+}
+
+func TestUnsupportedOption(t *testing.T) {
+	_, err := buildPanel(t, prometheus.PrometheusStatsTable("", nil, panels.Legend(timeSeriesPanel.Legend{})))
+	if err == nil || !strings.Contains(err.Error(), `panel "Prometheus Stats"`) {
+		t.Errorf("expected an error naming the panel, got %v", err)
+	}
+}
+
+func TestScopeLabels(t *testing.T) {
+	p, err := buildPanel(t, prometheus.PrometheusTargets("", labelMatchers, panels.ScopeLabels("cluster")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := queries(p)[0]
+	if !strings.Contains(spec.Query, "sum by (job, instance, cluster)") {
+		t.Errorf("expected the cluster label in the aggregation, got %q", spec.Query)
+	}
+	if spec.SeriesNameFormat != "{{cluster}} - {{job}} - {{instance}} - Metrics" {
+		t.Errorf("expected the cluster in the series names, got %q", spec.SeriesNameFormat)
+	}
+}
@@ -1,8 +1,9 @@
 package prometheus
 
 import (
-	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards"
+	"github.com/nicolastakashi/community-perses-dashboards/internal/dashboards/helpers"
 	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
 	"github.com/perses/perses/go-sdk/panel"
 	panelgroup "github.com/perses/perses/go-sdk/panel-group"
 	"github.com/perses/perses/go-sdk/prometheus/query"
@@ -20,7 +21,7 @@ import (
 // The panel shows:
 // - Instance count by job and version
 // - Version information per instance
-func PrometheusStatsTable(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusStatsTable(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Prometheus Stats",
 		tablePanel.Table(
 			tablePanel.WithColumnSettings([]tablePanel.ColumnSettings{
@@ -46,12 +47,12 @@ func PrometheusStatsTable(datasourceName string, labelMatchers ...promql.LabelMa
 				},
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("count by (job, instance, version) (prometheus_build_info{job=~'$job', instance=~'$instance'})", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-			),
+		helpers.AddPrometheusQuery(
+			"count by (job, instance, version) (prometheus_build_info{job=~'$job', instance=~'$instance'})",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -66,11 +67,12 @@ func PrometheusStatsTable(datasourceName string, labelMatchers ...promql.LabelMa
 //
 // Parameters:
 //   - datasourceName: The name of the data source.
-//   - labelMatchers: A variadic parameter for label matchers.
+//   - labelMatchers: Optional label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func PrometheusTargetSync(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusTargetSync(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Target Sync",
 		panel.Description("Monitors target synchronization time for Prometheus instances"),
 		timeSeriesPanel.Chart(
@@ -84,13 +86,13 @@ func PrometheusTargetSync(datasourceName string, labelMatchers ...promql.LabelMa
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum(rate(prometheus_target_sync_length_seconds_sum{job=~'$job',instance=~'$instance'}[5m])) by (job, scrape_job, instance)", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum(rate(prometheus_target_sync_length_seconds_sum{job=~'$job',instance=~'$instance'}[5m])) by (job, scrape_job, instance)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -105,11 +107,12 @@ func PrometheusTargetSync(datasourceName string, labelMatchers ...promql.LabelMa
 //
 // Parameters:
 //   - datasourceName: The name of the Prometheus datasource.
-//   - labelMatchers: Optional variadic parameter for PromQL label matchers.
+//   - labelMatchers: Optional PromQL label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func PrometheusTargets(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusTargets(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Targets",
 		panel.Description("Shows discovered targets across Prometheus instances"),
 		timeSeriesPanel.Chart(
@@ -118,13 +121,13 @@ func PrometheusTargets(datasourceName string, labelMatchers ...promql.LabelMatch
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum by (job, instance) (prometheus_sd_discovered_targets{job=~'$job',instance=~'$instance'})", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"sum by (job, instance) (prometheus_sd_discovered_targets{job=~'$job',instance=~'$instance'})",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -140,10 +143,11 @@ func PrometheusTargets(datasourceName string, labelMatchers ...promql.LabelMatch
 // Parameters:
 //   - datasourceName: The name of the Prometheus datasource.
 //   - labelMatchers: Optional PromQL label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func PrometheusAverageScrapeIntervalDuration(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusAverageScrapeIntervalDuration(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Average Scrape Interval Duration",
 		panel.Description("Shows average interval between scrapes for Prometheus targets"),
 		timeSeriesPanel.Chart(
@@ -157,15 +161,13 @@ func PrometheusAverageScrapeIntervalDuration(datasourceName string, labelMatcher
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("rate(prometheus_target_interval_length_seconds_sum{job=~'$job',instance=~'$instance'}[5m]) / rate(prometheus_target_interval_length_seconds_count{job=~'$job',instance=~'$instance'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - {{interval}} Configured"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_target_interval_length_seconds_sum{job=~'$job',instance=~'$instance'}[5m]) / rate(prometheus_target_interval_length_seconds_count{job=~'$job',instance=~'$instance'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - {{interval}} Configured"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -181,7 +183,7 @@ func PrometheusAverageScrapeIntervalDuration(datasourceName string, labelMatcher
 // The panel shows:
 // - Different types of scrape failures
 // - Rate of failures per type and target
-func PrometheusScrapeFailures(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusScrapeFailures(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Scrape failures",
 		panel.Description("Shows scrape failure metrics for Prometheus targets"),
 		timeSeriesPanel.Chart(
@@ -190,41 +192,37 @@ func PrometheusScrapeFailures(datasourceName string, labelMatchers ...promql.Lab
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum by (job, instance) (rate(prometheus_target_scrapes_exceeded_body_size_limit_total{job=~'$job',instance=~'$instance'}[1m]))", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("exceeded body size limit: {{job}} - {{instance}} - Metrics"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum by (job, instance) (rate(prometheus_target_scrapes_exceeded_sample_limit_total{job=~'$job',instance=~'$instance'}[1m]))", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("exceeded sample limit: {{job}} - {{instance}} - Metrics"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum by (job, instance) (rate(prometheus_target_scrapes_sample_duplicate_timestamp_total{job=~'$job',instance=~'$instance'}[1m]))", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("duplicate timestamp: {{job}} - {{instance}} - Metrics"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum by (job, instance) (rate(prometheus_target_scrapes_sample_out_of_bounds_total{job=~'$job',instance=~'$instance'}[1m]))", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("out of bounds: {{job}} - {{instance}} - Metrics"),
-			),
-		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("sum by (job, instance) (rate(prometheus_target_scrapes_sample_out_of_order_total{job=~'$job',instance=~'$instance'}[1m]))", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("out of order: {{job}} - {{instance}} - Metrics"),
-			),
-		),
+		helpers.AddPrometheusQuery(
+			"sum by (job, instance) (rate(prometheus_target_scrapes_exceeded_body_size_limit_total{job=~'$job',instance=~'$instance'}[1m]))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("exceeded body size limit: {{job}} - {{instance}} - Metrics"),
+		),
+		helpers.AddPrometheusQuery(
+			"sum by (job, instance) (rate(prometheus_target_scrapes_exceeded_sample_limit_total{job=~'$job',instance=~'$instance'}[1m]))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("exceeded sample limit: {{job}} - {{instance}} - Metrics"),
+		),
+		helpers.AddPrometheusQuery(
+			"sum by (job, instance) (rate(prometheus_target_scrapes_sample_duplicate_timestamp_total{job=~'$job',instance=~'$instance'}[1m]))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("duplicate timestamp: {{job}} - {{instance}} - Metrics"),
+		),
+		helpers.AddPrometheusQuery(
+			"sum by (job, instance) (rate(prometheus_target_scrapes_sample_out_of_bounds_total{job=~'$job',instance=~'$instance'}[1m]))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("out of bounds: {{job}} - {{instance}} - Metrics"),
+		),
+		helpers.AddPrometheusQuery(
+			"sum by (job, instance) (rate(prometheus_target_scrapes_sample_out_of_order_total{job=~'$job',instance=~'$instance'}[1m]))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("out of order: {{job}} - {{instance}} - Metrics"),
+		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -236,7 +234,7 @@ func PrometheusScrapeFailures(datasourceName string, labelMatchers ...promql.Lab
 // The panel shows:
 // - Rate of samples being appended
 // - Breakdown by job and instance
-func PrometheusAppendedSamples(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusAppendedSamples(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Appended Samples",
 		panel.Description("Shows rate of samples appended to Prometheus TSDB"),
 		timeSeriesPanel.Chart(
@@ -245,13 +243,13 @@ func PrometheusAppendedSamples(datasourceName string, labelMatchers ...promql.La
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("rate(prometheus_tsdb_head_samples_appended_total{job=~'$job',instance=~'$instance'}[5m])", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - {{remote_name}} - {{url}}"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_tsdb_head_samples_appended_total{job=~'$job',instance=~'$instance'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - {{remote_name}} - {{url}}"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -262,7 +260,7 @@ func PrometheusAppendedSamples(datasourceName string, labelMatchers ...promql.La
 // The panel shows:
 // - Current number of active series in TSDB head
 // - Breakdown by job and instance
-func PrometheusHeadSeries(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusHeadSeries(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Head Series",
 		panel.Description("Shows number of series in Prometheus TSDB head"),
 		timeSeriesPanel.Chart(
@@ -271,13 +269,13 @@ func PrometheusHeadSeries(datasourceName string, labelMatchers ...promql.LabelMa
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("prometheus_tsdb_head_series{job=~'$job',instance=~'$instance'}", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - Head Series"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_tsdb_head_series{job=~'$job',instance=~'$instance'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - Head Series"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -289,7 +287,7 @@ func PrometheusHeadSeries(datasourceName string, labelMatchers ...promql.LabelMa
 // The panel shows:
 // - Current number of chunks in TSDB head
 // - Breakdown by job and instance
-func PrometheusHeadChunks(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusHeadChunks(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Head Chunks",
 		panel.Description("Shows number of chunks in Prometheus TSDB head"),
 		timeSeriesPanel.Chart(
@@ -298,13 +296,13 @@ func PrometheusHeadChunks(datasourceName string, labelMatchers ...promql.LabelMa
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("prometheus_tsdb_head_chunks{job=~'$job',instance=~'$instance'}", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - Head Chunks"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_tsdb_head_chunks{job=~'$job',instance=~'$instance'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - Head Chunks"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -318,11 +316,12 @@ func PrometheusHeadChunks(datasourceName string, labelMatchers ...promql.LabelMa
 //
 // Parameters:
 //   - datasourceName: The name of the data source.
-//   - labelMatchers: A variadic parameter for label matchers.
+//   - labelMatchers: Optional label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func PrometheusQueryRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusQueryRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Query Rate",
 		panel.Description("Shows Prometheus query rate metrics"),
 		timeSeriesPanel.Chart(
@@ -331,13 +330,13 @@ func PrometheusQueryRate(datasourceName string, labelMatchers ...promql.LabelMat
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("rate(prometheus_engine_query_duration_seconds_count{job=~'$job',instance=~'$instance',slice='inner_eval'}[5m])", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{job}} - {{instance}} - Query Rate"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_engine_query_duration_seconds_count{job=~'$job',instance=~'$instance',slice='inner_eval'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{job}} - {{instance}} - Query Rate"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -353,11 +352,12 @@ func PrometheusQueryRate(datasourceName string, labelMatchers ...promql.LabelMat
 //
 // Parameters:
 //   - datasourceName: The name of the data source.
-//   - labelMatchers: A variadic parameter for label matchers.
+//   - labelMatchers: Optional label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func PrometheusQueryStateDuration(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusQueryStateDuration(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Stage Duration",
 		panel.Description("Shows duration of different Prometheus query stages"),
 		timeSeriesPanel.Chart(
@@ -371,13 +371,13 @@ func PrometheusQueryStateDuration(datasourceName string, labelMatchers ...promql
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers("max by (slice) (prometheus_engine_query_duration_seconds{quantile='0.9', job=~'$job',instance=~'$instance'})", labelMatchers),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{slice}} - Duration"),
-			),
+		helpers.AddPrometheusQuery(
+			"max by (slice) (prometheus_engine_query_duration_seconds{quantile='0.9', job=~'$job',instance=~'$instance'})",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{slice}} - Duration"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -392,7 +392,7 @@ func PrometheusQueryStateDuration(datasourceName string, labelMatchers ...promql
 // The panel shows:
 // - Lag between storage and queue timestamps
 // - Breakdown by remote storage target
-func PrometheusRemoteStorageTimestampLag(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageTimestampLag(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Timestamp Lag",
 		panel.Description("Shows timestamp lag in remote storage"),
 		timeSeriesPanel.Chart(
@@ -401,16 +401,13 @@ func PrometheusRemoteStorageTimestampLag(datasourceName string, labelMatchers ..
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"(prometheus_remote_storage_highest_timestamp_in_seconds{instance=~'$instance'} -  ignoring(remote_name, url) group_right(instance) (prometheus_remote_storage_queue_highest_sent_timestamp_seconds{instance=~'$instance', url='$url'} != 0))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Segment"),
-			),
+		helpers.AddPrometheusQuery(
+			"(prometheus_remote_storage_highest_timestamp_in_seconds{instance=~'$instance'} -  ignoring(remote_name, url) group_right(instance) (prometheus_remote_storage_queue_highest_sent_timestamp_seconds{instance=~'$instance', url='$url'} != 0))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Segment"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -423,25 +420,22 @@ func PrometheusRemoteStorageTimestampLag(datasourceName string, labelMatchers ..
 // The panel shows:
 // - Rate of lag between storage and queue timestamps
 // - 5-minute rate changes per target
-func PrometheusRemoteStorageRateLag(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
-	return panelgroup.AddPanel("Rate[5m]",
-		panel.Description("Shows rate metrics over 5 minute intervals"),
+func PrometheusRemoteStorageRateLag(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
+	return panelgroup.AddPanel("Rate",
+		panel.Description("Shows rate metrics over the rate interval"),
 		timeSeriesPanel.Chart(
 			timeSeriesPanel.WithLegend(timeSeriesPanel.Legend{
 				Position: timeSeriesPanel.BottomPosition,
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"clamp_min(rate(prometheus_remote_storage_highest_timestamp_in_seconds{instance=~'$instance'}[5m])  - ignoring (remote_name, url) group_right(instance) rate(prometheus_remote_storage_queue_highest_sent_timestamp_seconds{instance=~'$instance', url='$url'}[5m]), 0)",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"clamp_min(rate(prometheus_remote_storage_highest_timestamp_in_seconds{instance=~'$instance'}[5m])  - ignoring (remote_name, url) group_right(instance) rate(prometheus_remote_storage_queue_highest_sent_timestamp_seconds{instance=~'$instance', url='$url'}[5m]), 0)",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -459,12 +453,13 @@ func PrometheusRemoteStorageRateLag(datasourceName string, labelMatchers ...prom
 //
 // Parameters:
 //   - datasourceName: The name of the data source.
-//   - labelMatchers: A variadic parameter for label matchers.
+//   - labelMatchers: Optional label matchers.
+//   - options: Optional panel options overriding the defaults, such as the title or the unit.
 //
 // Returns:
 //   - panelgroup.Option: The configured panel option.
-func PrometheusRemoteStorageSampleRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
-	return panelgroup.AddPanel("Rate, in vs. succeeded or dropped [5m]",
+func PrometheusRemoteStorageSampleRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
+	return panelgroup.AddPanel("Rate, in vs. succeeded or dropped",
 		panel.Description("Shows rate of samples in remote storage"),
 		timeSeriesPanel.Chart(
 			timeSeriesPanel.WithLegend(timeSeriesPanel.Legend{
@@ -472,16 +467,13 @@ func PrometheusRemoteStorageSampleRate(datasourceName string, labelMatchers ...p
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(prometheus_remote_storage_samples_in_total{instance=~'$instance'}[5m]) - ignoring(remote_name, url) group_right(instance) (rate(prometheus_remote_storage_succeeded_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_total{instance=~'$instance', url='$url'}[5m])) - (rate(prometheus_remote_storage_dropped_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_dropped_total{instance=~'$instance', url='$url'}[5m]))",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_remote_storage_samples_in_total{instance=~'$instance'}[5m]) - ignoring(remote_name, url) group_right(instance) (rate(prometheus_remote_storage_succeeded_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_total{instance=~'$instance', url='$url'}[5m])) - (rate(prometheus_remote_storage_dropped_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_dropped_total{instance=~'$instance', url='$url'}[5m]))",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -494,7 +486,7 @@ func PrometheusRemoteStorageSampleRate(datasourceName string, labelMatchers ...p
 // The panel shows:
 // - Current shard count per target
 // - Breakdown by instance and URL
-func PrometheusRemoteStorageCurrentShards(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageCurrentShards(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Current Shards",
 		panel.Description("Shows current number of shards in remote storage"),
 		timeSeriesPanel.Chart(
@@ -503,16 +495,13 @@ func PrometheusRemoteStorageCurrentShards(datasourceName string, labelMatchers .
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_remote_storage_shards{instance=~'$instance', url='$url'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_remote_storage_shards{instance=~'$instance', url='$url'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -525,7 +514,7 @@ func PrometheusRemoteStorageCurrentShards(datasourceName string, labelMatchers .
 // The panel shows:
 // - Target shard count per remote storage
 // - Configuration vs actual shards
-func PrometheusRemoteStorageDesiredShards(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageDesiredShards(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Desired Shards",
 		panel.Description("Shows desired number of shards in remote storage"),
 		timeSeriesPanel.Chart(
@@ -534,16 +523,13 @@ func PrometheusRemoteStorageDesiredShards(datasourceName string, labelMatchers .
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_remote_storage_shards_desired{instance=~'$instance', url='$url'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_remote_storage_shards_desired{instance=~'$instance', url='$url'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -556,7 +542,7 @@ func PrometheusRemoteStorageDesiredShards(datasourceName string, labelMatchers .
 // The panel shows:
 // - Maximum shard limit per target
 // - Upper bounds for scaling
-func PrometheusRemoteStorageMaxShards(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageMaxShards(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Max Shards",
 		panel.Description("Shows maximum number of shards in remote storage"),
 		timeSeriesPanel.Chart(
@@ -565,16 +551,13 @@ func PrometheusRemoteStorageMaxShards(datasourceName string, labelMatchers ...pr
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_remote_storage_shards_max{instance=~'$instance', url='$url'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_remote_storage_shards_max{instance=~'$instance', url='$url'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -587,7 +570,7 @@ func PrometheusRemoteStorageMaxShards(datasourceName string, labelMatchers ...pr
 // The panel shows:
 // - Minimum shard requirement per target
 // - Lower bounds for scaling
-func PrometheusRemoteStorageMinShards(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageMinShards(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Min Shards",
 		panel.Description("Shows minimum number of shards in remote storage"),
 		timeSeriesPanel.Chart(
@@ -596,16 +579,13 @@ func PrometheusRemoteStorageMinShards(datasourceName string, labelMatchers ...pr
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_remote_storage_shards_min{instance=~'$instance', url='$url'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_remote_storage_shards_min{instance=~'$instance', url='$url'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -618,7 +598,7 @@ func PrometheusRemoteStorageMinShards(datasourceName string, labelMatchers ...pr
 // The panel shows:
 // - Shard capacity per remote storage target
 // - Breakdown by instance and URL
-func PrometheusRemoteStorageShardCapacity(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageShardCapacity(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Shard Capacity",
 		panel.Description("Shows shard capacity in remote storage"),
 		timeSeriesPanel.Chart(
@@ -627,16 +607,13 @@ func PrometheusRemoteStorageShardCapacity(datasourceName string, labelMatchers .
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_remote_storage_shard_capacity{instance=~'$instance', url='$url'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_remote_storage_shard_capacity{instance=~'$instance', url='$url'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -644,13 +621,13 @@ func PrometheusRemoteStorageShardCapacity(datasourceName string, labelMatchers .
 // in Prometheus remote storage.
 //
 // The panel uses the following Prometheus metrics:
-// - prometheus_remote_storage_pending_samples: Number of samples pending in remote storage
-// - prometheus_remote_storage_samples_pending: Legacy metric for pending samples
+// - prometheus_remote_storage_pending_samples: Number of samples pending in remote storage, before Prometheus 2.23
+// - prometheus_remote_storage_samples_pending: Number of samples pending in remote storage, since Prometheus 2.23
 //
 // The panel shows:
 // - Number of samples waiting to be sent
 // - Breakdown by remote storage target
-func PrometheusRemoteStoragePendingSamples(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStoragePendingSamples(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Pending Samples",
 		panel.Description("Shows number of pending samples in remote storage"),
 		timeSeriesPanel.Chart(
@@ -659,16 +636,13 @@ func PrometheusRemoteStoragePendingSamples(datasourceName string, labelMatchers
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_remote_storage_pending_samples{instance=~'$instance', url='$url'} or prometheus_remote_storage_samples_pending{instance=~'$instance', url='$url'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_remote_storage_pending_samples{instance=~'$instance', url='$url'} or prometheus_remote_storage_samples_pending{instance=~'$instance', url='$url'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -681,7 +655,7 @@ func PrometheusRemoteStoragePendingSamples(datasourceName string, labelMatchers
 // The panel shows:
 // - Current WAL segment number
 // - Segment progression over time
-func PrometheusTSDBCurrentSegment(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusTSDBCurrentSegment(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("TSDB Current Segment",
 		panel.Description("Shows current TSDB WAL segment"),
 		timeSeriesPanel.Chart(
@@ -690,16 +664,13 @@ func PrometheusTSDBCurrentSegment(datasourceName string, labelMatchers ...promql
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_tsdb_wal_segment_current{instance=~'$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Segment - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_tsdb_wal_segment_current{instance=~'$instance'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Segment - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -712,7 +683,7 @@ func PrometheusTSDBCurrentSegment(datasourceName string, labelMatchers ...promql
 // The panel shows:
 // - Current remote write WAL segment
 // - Segment progression over time
-func PrometheusRemoteWriteCurrentSegment(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteWriteCurrentSegment(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Remote Write Current Segment",
 		panel.Description("Shows current remote write WAL segment"),
 		timeSeriesPanel.Chart(
@@ -721,16 +692,13 @@ func PrometheusRemoteWriteCurrentSegment(datasourceName string, labelMatchers ..
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"prometheus_wal_watcher_current_segment{instance=~'$instance'}",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - Segment - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"prometheus_wal_watcher_current_segment{instance=~'$instance'}",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - Segment - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -738,13 +706,13 @@ func PrometheusRemoteWriteCurrentSegment(datasourceName string, labelMatchers ..
 // in Prometheus remote storage over a 5-minute interval.
 //
 // The panel uses the following Prometheus metrics:
-// - prometheus_remote_storage_dropped_samples_total: Total dropped samples
-// - prometheus_remote_storage_samples_dropped_total: Legacy metric for dropped samples
+// - prometheus_remote_storage_dropped_samples_total: Total dropped samples, before Prometheus 2.23
+// - prometheus_remote_storage_samples_dropped_total: Total dropped samples, since Prometheus 2.23
 //
 // The panel shows:
 // - Rate of sample drops per target
 // - Drop patterns over time
-func PrometheusRemoteStorageDroppedSamplesRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageDroppedSamplesRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Dropped Samples Rate",
 		panel.Description("Shows rate of dropped samples in remote storage"),
 		timeSeriesPanel.Chart(
@@ -753,16 +721,13 @@ func PrometheusRemoteStorageDroppedSamplesRate(datasourceName string, labelMatch
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(prometheus_remote_storage_dropped_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_dropped_total{instance=~'$instance', url='$url'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_remote_storage_dropped_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_dropped_total{instance=~'$instance', url='$url'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -770,13 +735,13 @@ func PrometheusRemoteStorageDroppedSamplesRate(datasourceName string, labelMatch
 // in Prometheus remote storage over a 5-minute interval.
 //
 // The panel uses the following Prometheus metrics:
-// - prometheus_remote_storage_failed_samples_total: Total failed samples
-// - prometheus_remote_storage_samples_failed_total: Legacy metric for failed samples
+// - prometheus_remote_storage_failed_samples_total: Total failed samples, before Prometheus 2.23
+// - prometheus_remote_storage_samples_failed_total: Total failed samples, since Prometheus 2.23
 //
 // The panel shows:
 // - Rate of sample failures per target
 // - Failure patterns over time
-func PrometheusRemoteStorageFailedSamplesRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageFailedSamplesRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Failed Samples",
 		panel.Description("Shows rate of failed samples in remote storage"),
 		timeSeriesPanel.Chart(
@@ -785,16 +750,13 @@ func PrometheusRemoteStorageFailedSamplesRate(datasourceName string, labelMatche
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(prometheus_remote_storage_failed_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_failed_total{instance=~'$instance', url='$url'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_remote_storage_failed_samples_total{instance=~'$instance', url='$url'}[5m]) or rate(prometheus_remote_storage_samples_failed_total{instance=~'$instance', url='$url'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -802,13 +764,13 @@ func PrometheusRemoteStorageFailedSamplesRate(datasourceName string, labelMatche
 // in Prometheus remote storage over a 5-minute interval.
 //
 // The panel uses the following Prometheus metrics:
-// - prometheus_remote_storage_retried_samples_total: Total retried samples
-// - prometheus_remote_storage_samples_retried_total: Legacy metric for retried samples
+// - prometheus_remote_storage_retried_samples_total: Total retried samples, before Prometheus 2.23
+// - prometheus_remote_storage_samples_retried_total: Total retried samples, since Prometheus 2.23
 //
 // The panel shows:
 // - Rate of sample retries per target
 // - Retry patterns over time
-func PrometheusRemoteStorageRetriedSamplesRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageRetriedSamplesRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Retried Samples",
 		panel.Description("Shows rate of retried samples in remote storage"),
 		timeSeriesPanel.Chart(
@@ -817,16 +779,13 @@ func PrometheusRemoteStorageRetriedSamplesRate(datasourceName string, labelMatch
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(prometheus_remote_storage_retried_samples_total{instance=~'$instance', url=~'$url'}[5m]) or rate(prometheus_remote_storage_samples_retried_total{instance=~'$instance', url=~'$url'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_remote_storage_retried_samples_total{instance=~'$instance', url=~'$url'}[5m]) or rate(prometheus_remote_storage_samples_retried_total{instance=~'$instance', url=~'$url'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
 
@@ -839,7 +798,7 @@ func PrometheusRemoteStorageRetriedSamplesRate(datasourceName string, labelMatch
 // The panel shows:
 // - Rate of enqueue retries per target
 // - Retry patterns over time
-func PrometheusRemoteStorageEnqueueRetriesRate(datasourceName string, labelMatchers ...promql.LabelMatcher) panelgroup.Option {
+func PrometheusRemoteStorageEnqueueRetriesRate(datasourceName string, labelMatchers []promql.LabelMatcher, options ...panels.Option) panelgroup.Option {
 	return panelgroup.AddPanel("Enqueue Retries",
 		panel.Description("Shows rate of enqueue retries in remote storage"),
 		timeSeriesPanel.Chart(
@@ -848,15 +807,12 @@ func PrometheusRemoteStorageEnqueueRetriesRate(datasourceName string, labelMatch
 				Mode:     timeSeriesPanel.TableMode,
 			}),
 		),
-		panel.AddQuery(
-			query.PromQL(
-				promql.SetLabelMatchers(
-					"rate(prometheus_remote_storage_enqueue_retries_total{instance=~'$instance', url=~'$url'}[5m])",
-					labelMatchers,
-				),
-				dashboards.AddQueryDataSource(datasourceName),
-				query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
-			),
+		helpers.AddPrometheusQuery(
+			"rate(prometheus_remote_storage_enqueue_retries_total{instance=~'$instance', url=~'$url'}[5m])",
+			labelMatchers,
+			helpers.AddQueryDataSource(datasourceName),
+			query.SeriesNameFormat("{{instance}} - {{remote_name}} - {{url}} - Metrics"),
 		),
+		panels.Apply(datasourceName, labelMatchers, options),
 	)
 }
@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/panels"
+)
+
+// Version is a Prometheus version, such as 2.53.0.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses a Prometheus version such as v2.53.3, 2.53.3 or 2.53, the missing parts being 0.
+func ParseVersion(version string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid Prometheus version %q", version)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid Prometheus version %q", version)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// Before tells whether the version is older than the other one.
+func (v Version) Before(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor < other.Minor
+	}
+	return v.Patch < other.Patch
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// MetricRename is a metric Prometheus renamed in a version.
+type MetricRename struct {
+	Old     string
+	New     string
+	Version Version
+}
+
+// MetricRenames lists the renamed metrics the panels query. Unless the panels target a Prometheus version,
+// their queries select both names, falling back with `or` from one to the other.
+var MetricRenames = []MetricRename{
+	{Old: "prometheus_remote_storage_succeeded_samples_total", New: "prometheus_remote_storage_samples_total", Version: Version{2, 23, 0}},
+	{Old: "prometheus_remote_storage_dropped_samples_total", New: "prometheus_remote_storage_samples_dropped_total", Version: Version{2, 23, 0}},
+	{Old: "prometheus_remote_storage_failed_samples_total", New: "prometheus_remote_storage_samples_failed_total", Version: Version{2, 23, 0}},
+	{Old: "prometheus_remote_storage_retried_samples_total", New: "prometheus_remote_storage_samples_retried_total", Version: Version{2, 23, 0}},
+	{Old: "prometheus_remote_storage_pending_samples", New: "prometheus_remote_storage_samples_pending", Version: Version{2, 23, 0}},
+}
+
+// TargetVersion makes the queries of the panel select only the names the renamed metrics have in the
+// Prometheus version, such as 2.53, dropping the `or` fallbacks to their other name. The queries are kept as
+// they are when the version is empty, unknown.
+func TargetVersion(version string) panels.Option {
+	return func(builder *panels.Builder) error {
+		return panels.QueryTransform(func(expr string) (string, error) {
+			return TargetVersionQuery(expr, version)
+		})(builder)
+	}
+}
+
+// TargetVersionQuery returns the query selecting only the names the renamed metrics have in the Prometheus
+// version, as TargetVersion does for the queries of a panel, such as the expressions of the alerts. The query
+// is returned as it is when the version is empty.
+func TargetVersionQuery(query string, version string) (string, error) {
+	if version == "" {
+		return query, nil
+	}
+	v, err := ParseVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return promql.RemoveFallbacks(query, func(metric string) bool {
+		return !exposedBy(metric, v)
+	})
+}
+
+// exposedBy tells whether the Prometheus version exposes the metric under that name, as far as MetricRenames
+// knows.
+func exposedBy(metric string, version Version) bool {
+	for _, rename := range MetricRenames {
+		switch metric {
+		case rename.Old:
+			return version.Before(rename.Version)
+		case rename.New:
+			return !version.Before(rename.Version)
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	panelgroup "github.com/perses/perses/go-sdk/panel-group"
+	"github.com/perses/perses/go-sdk/prometheus/query"
+)
+
+func TestParseVersion(t *testing.T) {
+	for version, want := range map[string]Version{
+		"2.53":    {2, 53, 0},
+		"v2.53.3": {2, 53, 3},
+		"3":       {3, 0, 0},
+	} {
+		got, err := ParseVersion(version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: expected %s, got %s", version, want, got)
+		}
+	}
+	for _, version := range []string{"", "2.x", "2.53.3.1", "2.-1"} {
+		if _, err := ParseVersion(version); err == nil {
+			t.Errorf("%q: expected an error", version)
+		}
+	}
+
+	if !(Version{2, 22, 2}).Before(Version{2, 23, 0}) || (Version{3, 0, 0}).Before(Version{2, 53, 0}) {
+		t.Error("unexpected version order")
+	}
+}
+
+// panelQuery returns the first query of the panel added to a panel group by the option.
+func panelQuery(t *testing.T, option panelgroup.Option) (string, error) {
+	t.Helper()
+	group, err := panelgroup.New("test", option)
+	if err != nil {
+		return "", err
+	}
+	return group.Panels[0].Spec.Queries[0].Spec.Plugin.Spec.(query.Builder).Query, nil
+}
+
+func TestTargetVersion(t *testing.T) {
+	const (
+		oldName = "prometheus_remote_storage_dropped_samples_total"
+		newName = "prometheus_remote_storage_samples_dropped_total"
+	)
+	for _, tc := range []struct {
+		version  string
+		selected []string
+		dropped  []string
+	}{
+		{version: "", selected: []string{oldName, newName}},
+		{version: "2.22.2", selected: []string{oldName}, dropped: []string{newName}},
+		{version: "v2.53", selected: []string{newName}, dropped: []string{oldName}},
+	} {
+		q, err := panelQuery(t, PrometheusRemoteStorageDroppedSamplesRate("", nil, TargetVersion(tc.version)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range tc.selected {
+			if !strings.Contains(q, name) {
+				t.Errorf("version %q: expected %s in %q", tc.version, name, q)
+			}
+		}
+		for _, name := range tc.dropped {
+			if strings.Contains(q, name) {
+				t.Errorf("version %q: expected %s to be dropped from %q", tc.version, name, q)
+			}
+		}
+	}
+
+	if _, err := panelQuery(t, PrometheusRemoteStoragePendingSamples("", nil, TargetVersion("2.x"))); err == nil {
+		t.Error("expected an error for an invalid version")
+	}
+}
@@ -0,0 +1,434 @@
+package promql
+
+import (
+	"errors"
+	"fmt"
+
+	internalPromql "github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// Query is a PromQL expression built with typed operations rather than written as a string, so that it can
+// be composed, scoped and shared between panels and rules:
+//
+//	Metric("node_cpu_seconds_total").
+//		Where(LabelMatcher{Name: "mode", Type: "=~", Value: "idle|iowait|steal"}).
+//		Rate("5m").
+//		SumWithout("mode")
+//
+// Queries are immutable, every operation returns a new query. The first invalid operation, such as the rate
+// of an aggregation, makes every following operation a no-op and is returned by Build.
+type Query struct {
+	// expr holds the expression along with the placeholder durations standing for the ranges given by
+	// variables, such as $__rate_interval, each query having its own.
+	expr *internalPromql.Expr
+	err  error
+}
+
+var errEmptyQuery = errors.New("empty query, start from Metric or Number")
+
+// Metric returns the query selecting the series of the metric with the label matchers. Unlike the ones
+// given to Where, the matchers with an empty value are kept, such as fstype!="" selecting the series having
+// the label.
+func Metric(name string, labelMatchers ...LabelMatcher) Query {
+	if !model.IsValidLegacyMetricName(name) {
+		return Query{err: fmt.Errorf("invalid metric name %q", name)}
+	}
+	selector := &parser.VectorSelector{
+		Name:          name,
+		LabelMatchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, name)},
+	}
+	for _, l := range labelMatchers {
+		matchType, err := l.MatchType()
+		if err == nil && !model.LabelName(l.Name).IsValidLegacy() {
+			err = fmt.Errorf("invalid label name %q", l.Name)
+		}
+		if err != nil {
+			return Query{err: fmt.Errorf("metric %s: %w", name, err)}
+		}
+		selector.LabelMatchers = append(selector.LabelMatchers, &labels.Matcher{Type: matchType, Name: l.Name, Value: l.Value})
+	}
+	return Query{expr: internalPromql.NewExpr(selector)}
+}
+
+// Number returns the query of a number, such as the 1 of 1 - x.
+func Number(value float64) Query {
+	return Query{expr: internalPromql.NewExpr(&parser.NumberLiteral{Val: value})}
+}
+
+// Where adds the label matchers to every vector selector of the query, replacing the matchers the selectors
+// already have on the same labels.
+func (q Query) Where(labelMatchers ...LabelMatcher) Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		return expr.SetLabelMatchers(labelMatchers)
+	})
+}
+
+// WithRange sets the range of every rate, irate and increase of the query to the window, a duration such as
+// 5m or a variable such as $__rate_interval.
+func (q Query) WithRange(window string) Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		return expr.SetRangeInterval(window)
+	})
+}
+
+// KeepLabels adds the labels to the by and on clauses of the query and removes them from its without and
+// ignoring clauses, so that the series differing by the labels, such as the series of two clusters, are
+// neither aggregated nor matched together.
+func (q Query) KeepLabels(groupingLabels ...string) Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		expr.SetGroupingLabels(groupingLabels)
+		return nil
+	})
+}
+
+// Rate returns the per-second rate of the metric selected by the query over the window, a duration such as
+// 5m or a variable such as $interval.
+func (q Query) Rate(window string) Query {
+	return q.rangeFunction("rate", window)
+}
+
+// IRate returns the per-second rate of the metric selected by the query, from the last two samples within
+// the window.
+func (q Query) IRate(window string) Query {
+	return q.rangeFunction("irate", window)
+}
+
+// Increase returns the increase of the metric selected by the query over the window.
+func (q Query) Increase(window string) Query {
+	return q.rangeFunction("increase", window)
+}
+
+// MaxOverTime returns the maximum value of each series of the metric selected by the query over the window.
+func (q Query) MaxOverTime(window string) Query {
+	return q.rangeFunction("max_over_time", window)
+}
+
+// MinOverTime returns the minimum value of each series of the metric selected by the query over the window.
+func (q Query) MinOverTime(window string) Query {
+	return q.rangeFunction("min_over_time", window)
+}
+
+// PredictLinear returns the value the metric selected by the query is predicted to have in the given number
+// of seconds, from a linear regression of its samples within the window.
+func (q Query) PredictLinear(window string, seconds float64) Query {
+	return q.rangeFunction("predict_linear", window, &parser.NumberLiteral{Val: seconds})
+}
+
+// rangeFunction returns the call of the function with the range of the metric selected by the query over the
+// window as first argument, followed by the other arguments.
+func (q Query) rangeFunction(name string, window string, args ...parser.Expr) Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		if _, ok := expr.AST.(*parser.VectorSelector); !ok {
+			return fmt.Errorf("%s: %s is not a metric selector", name, expr)
+		}
+		rangeDuration, err := expr.Duration(window)
+		if err != nil {
+			return err
+		}
+		expr.AST = &parser.Call{
+			Func: parser.Functions[name],
+			Args: append(parser.Expressions{&parser.MatrixSelector{VectorSelector: expr.AST, Range: rangeDuration}}, args...),
+		}
+		return nil
+	})
+}
+
+// Sum returns the sum of the series of the query.
+func (q Query) Sum() Query {
+	return q.aggregate(parser.SUM, false)
+}
+
+// SumBy returns the sum of the series of the query with the same values of the labels.
+func (q Query) SumBy(labels ...string) Query {
+	return q.aggregate(parser.SUM, false, labels...)
+}
+
+// SumWithout returns the sum of the series of the query with the same labels but the given ones.
+func (q Query) SumWithout(labels ...string) Query {
+	return q.aggregate(parser.SUM, true, labels...)
+}
+
+// Avg returns the average of the series of the query.
+func (q Query) Avg() Query {
+	return q.aggregate(parser.AVG, false)
+}
+
+// AvgBy returns the average of the series of the query with the same values of the labels.
+func (q Query) AvgBy(labels ...string) Query {
+	return q.aggregate(parser.AVG, false, labels...)
+}
+
+// AvgWithout returns the average of the series of the query with the same labels but the given ones.
+func (q Query) AvgWithout(labels ...string) Query {
+	return q.aggregate(parser.AVG, true, labels...)
+}
+
+// Count returns the number of series of the query.
+func (q Query) Count() Query {
+	return q.aggregate(parser.COUNT, false)
+}
+
+// CountBy returns the number of series of the query with the same values of the labels.
+func (q Query) CountBy(labels ...string) Query {
+	return q.aggregate(parser.COUNT, false, labels...)
+}
+
+// CountWithout returns the number of series of the query with the same labels but the given ones.
+func (q Query) CountWithout(labels ...string) Query {
+	return q.aggregate(parser.COUNT, true, labels...)
+}
+
+// MaxBy returns the maximum of the series of the query with the same values of the labels.
+func (q Query) MaxBy(labels ...string) Query {
+	return q.aggregate(parser.MAX, false, labels...)
+}
+
+// MaxWithout returns the maximum of the series of the query with the same labels but the given ones.
+func (q Query) MaxWithout(labels ...string) Query {
+	return q.aggregate(parser.MAX, true, labels...)
+}
+
+// MinBy returns the minimum of the series of the query with the same values of the labels.
+func (q Query) MinBy(labels ...string) Query {
+	return q.aggregate(parser.MIN, false, labels...)
+}
+
+func (q Query) aggregate(op parser.ItemType, without bool, grouping ...string) Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		expr.AST = &parser.AggregateExpr{Op: op, Expr: expr.AST, Grouping: grouping, Without: without}
+		return nil
+	})
+}
+
+// HistogramQuantile returns the φ-quantile of the histogram buckets of the query, such as 0.99.
+func (q Query) HistogramQuantile(phi float64) Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		expr.AST = &parser.Call{
+			Func: parser.Functions["histogram_quantile"],
+			Args: parser.Expressions{&parser.NumberLiteral{Val: phi}, expr.AST},
+		}
+		return nil
+	})
+}
+
+// Scalar returns the value of the only series of the query as a scalar, such as the total a ratio is
+// computed against, or NaN when the query has no or several series.
+func (q Query) Scalar() Query {
+	return q.update(func(expr *internalPromql.Expr) error {
+		expr.AST = &parser.Call{Func: parser.Functions["scalar"], Args: parser.Expressions{expr.AST}}
+		return nil
+	})
+}
+
+// Matching sets how the series of the operands of a binary operation are matched.
+type Matching func(matching *parser.VectorMatching)
+
+// On matches the series on the labels only.
+func On(labels ...string) Matching {
+	return func(matching *parser.VectorMatching) {
+		matching.On = true
+		matching.MatchingLabels = labels
+	}
+}
+
+// Ignoring matches the series on all their labels but the given ones.
+func Ignoring(labels ...string) Matching {
+	return func(matching *parser.VectorMatching) {
+		matching.On = false
+		matching.MatchingLabels = labels
+	}
+}
+
+// GroupLeft matches several series of the left operand with one of the right operand, copying the labels of
+// the right one.
+func GroupLeft(labels ...string) Matching {
+	return func(matching *parser.VectorMatching) {
+		matching.Card = parser.CardManyToOne
+		matching.Include = labels
+	}
+}
+
+// GroupRight matches several series of the right operand with one of the left operand, copying the labels of
+// the left one.
+func GroupRight(labels ...string) Matching {
+	return func(matching *parser.VectorMatching) {
+		matching.Card = parser.CardOneToMany
+		matching.Include = labels
+	}
+}
+
+// Add returns the query plus the other one.
+func (q Query) Add(other Query, matching ...Matching) Query {
+	return q.binary(parser.ADD, other, matching)
+}
+
+// Sub returns the query minus the other one.
+func (q Query) Sub(other Query, matching ...Matching) Query {
+	return q.binary(parser.SUB, other, matching)
+}
+
+// Mul returns the query times the other one.
+func (q Query) Mul(other Query, matching ...Matching) Query {
+	return q.binary(parser.MUL, other, matching)
+}
+
+// Div returns the query divided by the other one.
+func (q Query) Div(other Query, matching ...Matching) Query {
+	return q.binary(parser.DIV, other, matching)
+}
+
+// Or returns the series of the query, along with the series of the other one matching none of them.
+func (q Query) Or(other Query, matching ...Matching) Query {
+	return q.binary(parser.LOR, other, matching)
+}
+
+// And returns the series of the query matching a series of the other one.
+func (q Query) And(other Query, matching ...Matching) Query {
+	return q.binary(parser.LAND, other, matching)
+}
+
+// Equal keeps the series of the query whose value equals the other one.
+func (q Query) Equal(other Query, matching ...Matching) Query {
+	return q.binary(parser.EQLC, other, matching)
+}
+
+// NotEqual keeps the series of the query whose value differs from the other one, such as 0.
+func (q Query) NotEqual(other Query, matching ...Matching) Query {
+	return q.binary(parser.NEQ, other, matching)
+}
+
+// GreaterThan keeps the series of the query whose value is greater than the other one.
+func (q Query) GreaterThan(other Query, matching ...Matching) Query {
+	return q.binary(parser.GTR, other, matching)
+}
+
+// GreaterOrEqual keeps the series of the query whose value is greater than or equal to the other one.
+func (q Query) GreaterOrEqual(other Query, matching ...Matching) Query {
+	return q.binary(parser.GTE, other, matching)
+}
+
+// LessThan keeps the series of the query whose value is less than the other one.
+func (q Query) LessThan(other Query, matching ...Matching) Query {
+	return q.binary(parser.LSS, other, matching)
+}
+
+func (q Query) binary(op parser.ItemType, other Query, matching []Matching) Query {
+	// The error of the query comes first, being the one of the earliest operation.
+	if q.err != nil {
+		return q
+	}
+	if other.err != nil {
+		return other
+	}
+	if other.expr == nil {
+		return Query{err: errEmptyQuery}
+	}
+	return q.update(func(expr *internalPromql.Expr) error {
+		operand, err := other.expr.Clone()
+		if err != nil {
+			return err
+		}
+		// The ranges of the other query may stand for its variables with other placeholders than the query.
+		n := &parser.BinaryExpr{Op: op, LHS: parenthesize(expr.AST, op, false), RHS: parenthesize(expr.Import(operand), op, true)}
+		if n.LHS.Type() == parser.ValueTypeVector && n.RHS.Type() == parser.ValueTypeVector {
+			n.VectorMatching = &parser.VectorMatching{Card: parser.CardOneToOne}
+			if op.IsSetOperator() {
+				n.VectorMatching.Card = parser.CardManyToMany
+			}
+			for _, m := range matching {
+				m(n.VectorMatching)
+			}
+		} else if len(matching) > 0 {
+			return fmt.Errorf("%s: vector matching between a scalar and a vector", op)
+		}
+		expr.AST = n
+		return nil
+	})
+}
+
+// precedences are the precedences of the binary operators, from the PromQL grammar.
+var precedences = map[parser.ItemType]int{
+	parser.LOR:     1,
+	parser.LAND:    2,
+	parser.LUNLESS: 2,
+	parser.EQLC:    3,
+	parser.NEQ:     3,
+	parser.GTR:     3,
+	parser.GTE:     3,
+	parser.LSS:     3,
+	parser.LTE:     3,
+	parser.ADD:     4,
+	parser.SUB:     4,
+	parser.MUL:     5,
+	parser.DIV:     5,
+	parser.MOD:     5,
+	parser.ATAN2:   5,
+	parser.POW:     6,
+}
+
+// parenthesize returns the operand of a binary operation within parentheses when it is a binary operation
+// binding less tightly than op, or as tightly on the side op does not associate with, so that the formatted
+// query parses back to the same operations.
+func parenthesize(operand parser.Expr, op parser.ItemType, right bool) parser.Expr {
+	n, ok := operand.(*parser.BinaryExpr)
+	if !ok {
+		return operand
+	}
+	if p := precedences[n.Op]; p < precedences[op] || p == precedences[op] && right != (op == parser.POW) {
+		return &parser.ParenExpr{Expr: operand}
+	}
+	return operand
+}
+
+// update returns the query with a copy of its expression updated in place, so that updating a query never
+// updates the queries it was built from or combined with.
+func (q Query) update(update func(expr *internalPromql.Expr) error) Query {
+	if q.err != nil {
+		return q
+	}
+	if q.expr == nil {
+		return Query{err: errEmptyQuery}
+	}
+	expr, err := q.expr.Clone()
+	if err == nil {
+		err = update(expr)
+	}
+	if err != nil {
+		return Query{err: err}
+	}
+	return Query{expr: expr}
+}
+
+// Expr returns a copy of the AST of the query, or the first error of its operations. A range given by a
+// variable, such as $__rate_interval, is not a duration the AST can hold, so Expr fails for the queries
+// having one, which only Build formats.
+func (q Query) Expr() (parser.Expr, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if q.expr == nil {
+		return nil, errEmptyQuery
+	}
+	if q.expr.HasVariables() {
+		return nil, fmt.Errorf("the ranges of %s are given by variables", q.expr)
+	}
+	expr, err := q.expr.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return expr.AST, nil
+}
+
+// Build returns the formatted query, with its variables, or the first error of its operations.
+func (q Query) Build() (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	if q.expr == nil {
+		return "", errEmptyQuery
+	}
+	return q.expr.String(), nil
+}
@@ -0,0 +1,144 @@
+package promql
+
+import (
+	"strings"
+	"testing"
+
+	internalPromql "github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+func TestQuery(t *testing.T) {
+	requests := Metric("http_requests_total").Where(LabelMatcher{Name: "job", Type: "=", Value: "api"})
+	errors := requests.Where(LabelMatcher{Name: "code", Type: "=~", Value: "5.."})
+
+	for _, tc := range []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{
+			name:  "rate by label",
+			query: requests.Rate("$interval").SumBy("instance"),
+			want:  `sum by (instance) (rate(http_requests_total{job="api"}[$interval]))`,
+		},
+		{
+			name:  "ratio",
+			query: errors.Rate("5m").SumBy("instance").Div(requests.Rate("5m").SumBy("instance")),
+			want:  `sum by (instance) (rate(http_requests_total{code=~"5..",job="api"}[5m])) / sum by (instance) (rate(http_requests_total{job="api"}[5m]))`,
+		},
+		{
+			name:  "vector matching",
+			query: Number(1).Sub(requests).Div(requests.Count(), Ignoring("code"), GroupLeft()),
+			want:  `(1 - http_requests_total{job="api"}) / ignoring (code) group_left () count(http_requests_total{job="api"})`,
+		},
+		{
+			name:  "structural scoping",
+			query: requests.Rate("5m").SumBy("instance").Where(LabelMatcher{Name: "cluster", Type: "=~", Value: "$cluster"}).KeepLabels("cluster").WithRange("$__rate_interval"),
+			want:  `sum by (instance, cluster) (rate(http_requests_total{cluster=~"$cluster",job="api"}[$__rate_interval]))`,
+		},
+		{
+			name:  "ranges given by variables",
+			query: requests.Rate("$a").Sum().Div(Metric("b").Rate("$b").Sum().Add(Metric("c").Rate("$a").Sum())),
+			want:  `sum(rate(http_requests_total{job="api"}[$a])) / (sum(rate(b[$b])) + sum(rate(c[$a])))`,
+		},
+		{
+			name:  "ranges given by variables in another order",
+			query: Metric("b").Rate("$b").Sum().Add(requests.Rate("$a").Sum()).WithRange("$c"),
+			want:  `sum(rate(b[$c])) + sum(rate(http_requests_total{job="api"}[$c]))`,
+		},
+		{
+			name:  "metric matchers",
+			query: Metric("node_filesystem_avail_bytes", LabelMatcher{Name: "fstype", Type: "!=", Value: ""}).Where(LabelMatcher{Name: "instance", Type: "=", Value: "$instance"}),
+			want:  `node_filesystem_avail_bytes{fstype!="",instance="$instance"}`,
+		},
+		{
+			name:  "comparisons",
+			query: requests.Div(Metric("http_requests_limit")).Mul(Number(100)).LessThan(Number(15)).And(Metric("up").Equal(Number(1))),
+			want:  `http_requests_total{job="api"} / http_requests_limit * 100 < 15 and up == 1`,
+		},
+		{
+			name:  "functions over time",
+			query: Metric("up").PredictLinear("6h", 3600).GreaterOrEqual(Metric("up").MaxOverTime("1h").MaxWithout("job")).Or(Metric("up").MinOverTime("$__range")),
+			want:  `predict_linear(up[6h], 3600) >= max without (job) (max_over_time(up[1h])) or min_over_time(up[$__range])`,
+		},
+		{
+			name:  "scalar",
+			query: requests.NotEqual(Number(0)).Div(requests.Count().Scalar()),
+			want:  `(http_requests_total{job="api"} != 0) / scalar(count(http_requests_total{job="api"}))`,
+		},
+		{
+			name:  "histogram quantile",
+			query: Metric("http_request_duration_seconds_bucket").Rate("5m").SumBy("le").HistogramQuantile(0.99),
+			want:  `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket[5m])))`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.query.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(strings.Fields(got), " ") != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+			if _, err := internalPromql.ParseExpr(got); err != nil {
+				t.Errorf("invalid query %s: %v", got, err)
+			}
+		})
+	}
+
+	// The queries a query was built from are left untouched.
+	if got, _ := requests.Build(); got != `http_requests_total{job="api"}` {
+		t.Errorf("expected the base query to be unchanged, got %s", got)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for name, q := range map[string]Query{
+		"rate of an aggregation": Metric("up").Sum().Rate("5m"),
+		"invalid range":          Metric("up").Rate("5 minutes"),
+		"invalid matcher":        Metric("up").Where(LabelMatcher{Name: "job", Type: "==", Value: "api"}),
+		"scalar matching":        Number(1).Sub(Metric("up"), On("job")),
+		"empty metric name":      Metric(""),
+		"metric variable":        Metric("$metric"),
+		"invalid metric name":    Metric("foo-bar"),
+		"invalid metric matcher": Metric("up", LabelMatcher{Name: "job", Type: "==", Value: "api"}),
+		"invalid label name":     Metric("up", LabelMatcher{Name: "job-name", Type: "=", Value: "api"}),
+		"invalid operand":        Number(1).Sub(Metric("foo-bar")),
+		"empty query":            Query{}.Rate("5m"),
+		"empty operand":          Metric("up").Div(Query{}),
+	} {
+		if _, err := q.Sum().Build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// The error of the first operation is returned, even when the other operand of a binary operation failed too.
+	_, err := Metric("foo-bar").Div(Metric("bar-baz")).Build()
+	if err == nil || !strings.Contains(err.Error(), "foo-bar") {
+		t.Errorf("expected the error of the left operand, got %v", err)
+	}
+}
+
+func TestQueryExpr(t *testing.T) {
+	q := Metric("up").Rate("5m").SumBy("job")
+	expr, err := q.Expr()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := q.Build(); expr.String() != want {
+		t.Errorf("expected %s, got %s", want, expr)
+	}
+	// The returned AST is a copy, updating it leaves the query untouched.
+	expr.(*parser.AggregateExpr).Grouping = nil
+	if got, _ := q.Build(); got != "sum by (job) (rate(up[5m]))" {
+		t.Errorf("expected the query to be unchanged, got %s", got)
+	}
+
+	if _, err := q.WithRange("$__rate_interval").Expr(); err == nil {
+		t.Error("expected an error for a range given by a variable")
+	}
+	if _, err := Metric("foo-bar").Expr(); err == nil {
+		t.Error("expected the error of the query")
+	}
+}
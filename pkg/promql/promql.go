@@ -0,0 +1,10 @@
+// Package promql holds the PromQL types taken by the public builders, such as the label matchers injected in
+// the queries of the panels, alerts and dashboards, and a typed builder of the queries shared by the panels
+// and the rules.
+package promql
+
+import internalPromql "github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+
+// LabelMatcher is a label matcher of a PromQL selector, such as job=~"node.*", given by its Name, its match
+// Type, one of =, !=, =~ and !~, and its Value.
+type LabelMatcher = internalPromql.LabelMatcher
@@ -0,0 +1,252 @@
+package nodeexporter
+
+import (
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+// Names of the series recorded by the node exporter recording rules. The cluster panels
+// in pkg/panels/node_exporter query these names, so they must not be changed independently.
+const (
+	NodeNumCPUSum                             = "instance:node_num_cpu:sum"
+	NodeCPUUtilisationRate5m                  = "instance:node_cpu_utilisation:rate5m"
+	NodeLoad1PerCPURatio                      = "instance:node_load1_per_cpu:ratio"
+	NodeMemoryUtilisationRatio                = "instance:node_memory_utilisation:ratio"
+	NodeVmstatPgmajfaultRate5m                = "instance:node_vmstat_pgmajfault:rate5m"
+	NodeDiskIOTimeSecondsRate5m               = "instance_device:node_disk_io_time_seconds:rate5m"
+	NodeDiskIOTimeWeightedSecondsRate5m       = "instance_device:node_disk_io_time_weighted_seconds:rate5m"
+	NodeNetworkReceiveBytesExcludingLoRate5m  = "instance:node_network_receive_bytes_excluding_lo:rate5m"
+	NodeNetworkTransmitBytesExcludingLoRate5m = "instance:node_network_transmit_bytes_excluding_lo:rate5m"
+	NodeNetworkReceiveDropExcludingLoRate5m   = "instance:node_network_receive_drop_excluding_lo:rate5m"
+	NodeNetworkTransmitDropExcludingLoRate5m  = "instance:node_network_transmit_drop_excluding_lo:rate5m"
+)
+
+const diskDeviceSelector = "device=~'(/dev/)?(mmcblk.p.+|nvme.+|rbd.+|sd.+|vd.+|xvd.+|dm-.+|md.+|dasd.+)'"
+
+// RecordNodeNumCPUSum records the number of logical CPUs of each instance.
+//
+// The rule uses the following Prometheus metrics:
+// - node_cpu_seconds_total: CPU time spent in different modes
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeNumCPUSum(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRuleQuery(NodeNumCPUSum, NumCPU(), labelMatchers)
+}
+
+// NumCPU returns the query of the number of logical CPUs of each instance, shared by the CPU count rule and
+// the CPU usage panel.
+func NumCPU() promql.Query {
+	return promql.Metric("node_cpu_seconds_total").
+		Where(promql.LabelMatcher{Name: "mode", Type: "=", Value: "idle"}).
+		CountWithout("cpu", "mode")
+}
+
+// RecordNodeCPUUtilisationRate5m records the CPU utilisation ratio of each instance,
+// excluding idle, iowait and steal time.
+//
+// The rule uses the following Prometheus metrics:
+// - node_cpu_seconds_total: CPU time spent in different modes
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeCPUUtilisationRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRuleQuery(NodeCPUUtilisationRate5m,
+		promql.Number(1).Sub(CPUIdleRate("5m").AvgWithout("cpu")),
+		labelMatchers,
+	)
+}
+
+// CPUIdleRate returns the query of the fraction of time each CPU of each instance spent idle, waiting for
+// I/O or stolen by the hypervisor over the window, shared by the CPU utilisation rule and panels.
+func CPUIdleRate(window string) promql.Query {
+	return promql.Metric("node_cpu_seconds_total").
+		Where(promql.LabelMatcher{Name: "mode", Type: "=~", Value: "idle|iowait|steal"}).
+		Rate(window).
+		SumWithout("mode")
+}
+
+// RecordNodeLoad1PerCPURatio records the 1-minute load average of each instance divided
+// by its number of CPUs. It depends on the series recorded by RecordNodeNumCPUSum.
+//
+// The rule uses the following Prometheus metrics:
+// - node_load1: 1-minute load average
+// - instance:node_num_cpu:sum: Number of logical CPUs
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeLoad1PerCPURatio(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeLoad1PerCPURatio,
+		"node_load1 / "+NodeNumCPUSum,
+		labelMatchers,
+	)
+}
+
+// RecordNodeMemoryUtilisationRatio records the memory utilisation ratio of each instance.
+// Kernels that do not expose MemAvailable fall back to the sum of buffers, cache, free and slab memory.
+//
+// The rule uses the following Prometheus metrics:
+// - node_memory_MemAvailable_bytes: Available memory in bytes
+// - node_memory_Buffers_bytes, node_memory_Cached_bytes, node_memory_MemFree_bytes, node_memory_Slab_bytes: Reclaimable memory
+// - node_memory_MemTotal_bytes: Total physical memory in bytes
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeMemoryUtilisationRatio(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeMemoryUtilisationRatio,
+		"1 - ((node_memory_MemAvailable_bytes or (node_memory_Buffers_bytes + node_memory_Cached_bytes + node_memory_MemFree_bytes + node_memory_Slab_bytes)) / node_memory_MemTotal_bytes)",
+		labelMatchers,
+	)
+}
+
+// RecordNodeVmstatPgmajfaultRate5m records the rate of major page faults of each instance.
+//
+// The rule uses the following Prometheus metrics:
+// - node_vmstat_pgmajfault: Number of major page faults
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeVmstatPgmajfaultRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeVmstatPgmajfaultRate5m,
+		"rate(node_vmstat_pgmajfault[5m])",
+		labelMatchers,
+	)
+}
+
+// RecordNodeDiskIOTimeSecondsRate5m records the fraction of time each disk device spent doing I/O.
+//
+// The rule uses the following Prometheus metrics:
+// - node_disk_io_time_seconds_total: Total seconds spent doing I/O
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeDiskIOTimeSecondsRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeDiskIOTimeSecondsRate5m,
+		"rate(node_disk_io_time_seconds_total{"+diskDeviceSelector+"}[5m])",
+		labelMatchers,
+	)
+}
+
+// RecordNodeDiskIOTimeWeightedSecondsRate5m records the weighted time each disk device spent doing I/O,
+// which approximates the average I/O queue length.
+//
+// The rule uses the following Prometheus metrics:
+// - node_disk_io_time_weighted_seconds_total: Weighted seconds spent doing I/O
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeDiskIOTimeWeightedSecondsRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeDiskIOTimeWeightedSecondsRate5m,
+		"rate(node_disk_io_time_weighted_seconds_total{"+diskDeviceSelector+"}[5m])",
+		labelMatchers,
+	)
+}
+
+// RecordNodeNetworkReceiveBytesExcludingLoRate5m records the bytes received per second by each
+// instance across all network devices except the loopback device.
+//
+// The rule uses the following Prometheus metrics:
+// - node_network_receive_bytes_total: Total bytes received over network
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeNetworkReceiveBytesExcludingLoRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRuleQuery(NodeNetworkReceiveBytesExcludingLoRate5m,
+		NetworkReceiveBytesRate("5m").SumWithout("device"),
+		labelMatchers,
+	)
+}
+
+// NetworkReceiveBytesRate returns the query of the bytes received per second by each network device of
+// each instance over the window, except the loopback device, shared by the network rule and panels.
+func NetworkReceiveBytesRate(window string) promql.Query {
+	return promql.Metric("node_network_receive_bytes_total").
+		Where(promql.LabelMatcher{Name: "device", Type: "!=", Value: "lo"}).
+		Rate(window)
+}
+
+// RecordNodeNetworkTransmitBytesExcludingLoRate5m records the bytes transmitted per second by each
+// instance across all network devices except the loopback device.
+//
+// The rule uses the following Prometheus metrics:
+// - node_network_transmit_bytes_total: Total bytes transmitted over network
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeNetworkTransmitBytesExcludingLoRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRuleQuery(NodeNetworkTransmitBytesExcludingLoRate5m,
+		NetworkTransmitBytesRate("5m").SumWithout("device"),
+		labelMatchers,
+	)
+}
+
+// NetworkTransmitBytesRate returns the query of the bytes transmitted per second by each network device of
+// each instance over the window, except the loopback device, shared by the network rule and panels.
+func NetworkTransmitBytesRate(window string) promql.Query {
+	return promql.Metric("node_network_transmit_bytes_total").
+		Where(promql.LabelMatcher{Name: "device", Type: "!=", Value: "lo"}).
+		Rate(window)
+}
+
+// RecordNodeNetworkReceiveDropExcludingLoRate5m records the received packets dropped per second by
+// each instance across all network devices except the loopback device.
+//
+// The rule uses the following Prometheus metrics:
+// - node_network_receive_drop_total: Total received packets dropped
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeNetworkReceiveDropExcludingLoRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeNetworkReceiveDropExcludingLoRate5m,
+		"sum without (device) (rate(node_network_receive_drop_total{device!='lo'}[5m]))",
+		labelMatchers,
+	)
+}
+
+// RecordNodeNetworkTransmitDropExcludingLoRate5m records the transmitted packets dropped per second by
+// each instance across all network devices except the loopback device.
+//
+// The rule uses the following Prometheus metrics:
+// - node_network_transmit_drop_total: Total transmitted packets dropped
+//
+// Parameters:
+//   - labelMatchers: A variadic parameter for Prometheus label matchers to filter the expression.
+//
+// Returns:
+//   - rules.GroupOption: A rule option that can be added to a rule group.
+func RecordNodeNetworkTransmitDropExcludingLoRate5m(labelMatchers ...promql.LabelMatcher) rules.GroupOption {
+	return rules.AddRecordingRule(NodeNetworkTransmitDropExcludingLoRate5m,
+		"sum without (device) (rate(node_network_transmit_drop_total{device!='lo'}[5m]))",
+		labelMatchers,
+	)
+}
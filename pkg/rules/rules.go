@@ -0,0 +1,198 @@
+package rules
+
+import (
+	"fmt"
+
+	internalPromql "github.com/nicolastakashi/community-perses-dashboards/internal/promql"
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/promql"
+)
+
+// RuleGroup is a named set of rules evaluated together by Prometheus, as found in a rule file.
+type RuleGroup struct {
+	Name     string `json:"name" yaml:"name"`
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Rules    []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule is either a recording rule (Record is set) or an alerting rule (Alert is set).
+type Rule struct {
+	Record      string            `json:"record,omitempty" yaml:"record,omitempty"`
+	Alert       string            `json:"alert,omitempty" yaml:"alert,omitempty"`
+	Expr        string            `json:"expr" yaml:"expr"`
+	For         string            `json:"for,omitempty" yaml:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// RuleFile is the content of a Prometheus rule file.
+type RuleFile struct {
+	Groups []RuleGroup `json:"groups" yaml:"groups"`
+}
+
+// Builder holds a rule file together with the name used to write it.
+type Builder struct {
+	Name     string
+	RuleFile RuleFile
+}
+
+type Option func(builder *Builder) error
+
+type GroupOption func(group *RuleGroup) error
+
+// New creates a rule file builder named after the mixin it belongs to.
+func New(name string, options ...Option) (Builder, error) {
+	builder := &Builder{
+		Name: name,
+	}
+
+	for _, opt := range options {
+		if err := opt(builder); err != nil {
+			return *builder, err
+		}
+	}
+
+	return *builder, nil
+}
+
+// AddRuleGroup adds a rule group to the rule file.
+func AddRuleGroup(name string, options ...GroupOption) Option {
+	return func(builder *Builder) error {
+		group := &RuleGroup{
+			Name: name,
+		}
+		for _, opt := range options {
+			if err := opt(group); err != nil {
+				return err
+			}
+		}
+		builder.RuleFile.Groups = append(builder.RuleFile.Groups, *group)
+		return nil
+	}
+}
+
+// Interval sets how often the rules of the group are evaluated.
+func Interval(interval string) GroupOption {
+	return func(group *RuleGroup) error {
+		group.Interval = interval
+		return nil
+	}
+}
+
+// AddRecordingRule adds a recording rule to the group. The label matchers are injected
+// in every vector selector of the expression, which must be a valid PromQL expression.
+func AddRecordingRule(record string, expr string, labelMatchers []promql.LabelMatcher) GroupOption {
+	return func(group *RuleGroup) error {
+		expr, err := internalPromql.SetLabelMatchers(expr, labelMatchers)
+		if err != nil {
+			return fmt.Errorf("recording rule %q: %w", record, err)
+		}
+		group.Rules = append(group.Rules, Rule{
+			Record: record,
+			Expr:   expr,
+		})
+		return nil
+	}
+}
+
+// AddRecordingRuleQuery adds a recording rule whose expression is the query of a PromQL builder, so that
+// the panels can share the query. The label matchers are added to every vector selector of the query.
+func AddRecordingRuleQuery(record string, q promql.Query, labelMatchers []promql.LabelMatcher) GroupOption {
+	return func(group *RuleGroup) error {
+		expr, err := q.Where(labelMatchers...).Build()
+		if err != nil {
+			return fmt.Errorf("recording rule %q: %w", record, err)
+		}
+		group.Rules = append(group.Rules, Rule{
+			Record: record,
+			Expr:   expr,
+		})
+		return nil
+	}
+}
+
+// AddAlertingRule adds an alerting rule to the group. The label matchers are injected
+// in every vector selector of the rule expression.
+func AddAlertingRule(rule Rule, labelMatchers []promql.LabelMatcher) GroupOption {
+	return func(group *RuleGroup) error {
+		expr, err := internalPromql.SetLabelMatchers(rule.Expr, labelMatchers)
+		if err != nil {
+			return fmt.Errorf("alerting rule %q: %w", rule.Alert, err)
+		}
+		rule.Expr = expr
+		group.Rules = append(group.Rules, rule)
+		return nil
+	}
+}
+
+// AddAlertingRuleQuery adds an alerting rule whose expression is the query of a PromQL builder, so that the
+// alerts can share their queries with the panels and rules. The label matchers are added to every vector
+// selector of the query, which replaces the expression of the rule.
+func AddAlertingRuleQuery(rule Rule, q promql.Query, labelMatchers []promql.LabelMatcher) GroupOption {
+	return func(group *RuleGroup) error {
+		expr, err := q.Where(labelMatchers...).Build()
+		if err != nil {
+			return fmt.Errorf("alerting rule %q: %w", rule.Alert, err)
+		}
+		rule.Expr = expr
+		group.Rules = append(group.Rules, rule)
+		return nil
+	}
+}
+
+// Rules combines several rule options into a single one, for builders that add more than one
+// rule, such as the warning and critical variants of the same alert.
+func Rules(options ...GroupOption) GroupOption {
+	return func(group *RuleGroup) error {
+		for _, opt := range options {
+			if err := opt(group); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ExprTransform rewrites the expression of every rule already in the rule file, for instance to select the
+// metric names of an exporter version.
+func ExprTransform(transform func(expr string) (string, error)) Option {
+	return func(builder *Builder) error {
+		for i := range builder.RuleFile.Groups {
+			for j := range builder.RuleFile.Groups[i].Rules {
+				rule := &builder.RuleFile.Groups[i].Rules[j]
+				expr, err := transform(rule.Expr)
+				if err != nil {
+					return fmt.Errorf("rule %q: %w", rule.Alert+rule.Record, err)
+				}
+				rule.Expr = expr
+			}
+		}
+		return nil
+	}
+}
+
+// Labels adds the labels to every alerting rule already in the rule file. The labels a rule sets itself are
+// kept. The recording rules are left untouched, since a recorded series carrying labels the series it is
+// joined with lack, such as node_load1 / instance:node_num_cpu:sum, would no longer match them.
+func Labels(labels map[string]string) Option {
+	return func(builder *Builder) error {
+		for i := range builder.RuleFile.Groups {
+			group := &builder.RuleFile.Groups[i]
+			for j := range group.Rules {
+				rule := &group.Rules[j]
+				if rule.Alert == "" {
+					continue
+				}
+				for name, value := range labels {
+					if _, ok := rule.Labels[name]; ok {
+						continue
+					}
+					if rule.Labels == nil {
+						rule.Labels = map[string]string{}
+					}
+					rule.Labels[name] = value
+				}
+			}
+		}
+		return nil
+	}
+}
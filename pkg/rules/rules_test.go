@@ -0,0 +1,36 @@
+package rules_test
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/nicolastakashi/community-perses-dashboards/pkg/rules"
+)
+
+func TestLabels(t *testing.T) {
+	builder, err := rules.New("test",
+		rules.AddRuleGroup("test",
+			rules.AddRecordingRule("instance:up:sum", "sum by (instance) (up)", nil),
+			rules.AddAlertingRule(rules.Rule{Alert: "TargetDown", Expr: "up == 0", Labels: map[string]string{"severity": "critical"}}, nil),
+			rules.AddAlertingRule(rules.Rule{Alert: "TargetFlapping", Expr: "changes(up[10m]) > 2", Labels: map[string]string{"environment": "staging"}}, nil),
+		),
+		rules.Labels(map[string]string{"environment": "production"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]map[string]string{
+		// The recorded series keep the labels of the series they are joined with.
+		"instance:up:sum": nil,
+		"TargetDown":      {"severity": "critical", "environment": "production"},
+		// The labels a rule sets itself are kept.
+		"TargetFlapping": {"environment": "staging"},
+	}
+	for _, rule := range builder.RuleFile.Groups[0].Rules {
+		name := rule.Alert + rule.Record
+		if !maps.Equal(rule.Labels, want[name]) {
+			t.Errorf("%s: expected the labels %v, got %v", name, want[name], rule.Labels)
+		}
+	}
+}